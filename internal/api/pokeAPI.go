@@ -1,26 +1,208 @@
 package api
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
+	"math/rand"
+	"net"
 	"net/http"
+	"net/url"
+	"strconv"
+	"time"
 )
 
-func GetLocations(url string) ([]byte, error) {
-	res, err := http.Get(url)
+var (
+	ErrNotFound    = errors.New("resource not found")
+	ErrRateLimited = errors.New("rate limited by the API")
+	ErrUpstream    = errors.New("upstream API error")
+)
+
+type StatusError struct {
+	URL        string
+	StatusCode int
+	kind       error
+	retryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%v: %v returned status code %d", e.kind, e.URL, e.StatusCode)
+}
+
+func (e *StatusError) Unwrap() error {
+	return e.kind
+}
+
+type Client struct {
+	httpClient *http.Client
+	baseURL    string
+	maxRetries int
+	baseDelay  time.Duration
+	maxDelay   time.Duration
+	// maxRetryAfter is the longest Retry-After worth waiting for, the client
+	// gives up on longer ones.
+	maxRetryAfter time.Duration
+}
+
+// NewClient talks to the PokeAPI instance at baseURL, which must end with a
+// slash like DefaultBaseURL.
+func NewClient(baseURL string, timeout time.Duration) Client {
+	return Client{
+		httpClient: &http.Client{Timeout: timeout},
+		baseURL:    baseURL,
+		maxRetries: 3,
+		baseDelay:  200 * time.Millisecond,
+		maxDelay:   5 * time.Second,
+
+		maxRetryAfter: 30 * time.Second,
+	}
+}
+
+type Response struct {
+	Body         []byte
+	ETag         string
+	LastModified string
+	NotModified  bool
+}
+
+func (c Client) BaseURL() string {
+	return c.baseURL
+}
+
+func (c Client) Get(ctx context.Context, url string) ([]byte, error) {
+	res, err := c.GetConditional(ctx, url, "", "")
 	if err != nil {
 		return make([]byte, 0), err
 	}
+	return res.Body, nil
+}
 
-	if res.StatusCode > 299 {
-		return make([]byte, 0), errors.New(fmt.Sprintln("Failed with the status code: %d", res.StatusCode))
+func (c Client) GetConditional(ctx context.Context, url, etag, lastModified string) (Response, error) {
+	var lastErr error
+	for attempt := 0; attempt <= c.maxRetries; attempt++ {
+		if attempt > 0 {
+			delay, ok := c.backoff(attempt, lastErr)
+			if !ok {
+				break
+			}
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return Response{}, ctx.Err()
+			}
+		}
+		res, err := c.do(ctx, url, etag, lastModified)
+		if err == nil {
+			return res, nil
+		}
+		lastErr = err
+		if !retryable(ctx, err) {
+			break
+		}
+	}
+	return Response{}, lastErr
+}
+
+func (c Client) do(ctx context.Context, url, etag, lastModified string) (Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return Response{}, err
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
 	}
 
-	body, err1 := io.ReadAll(res.Body)
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return Response{}, err
+	}
 	defer res.Body.Close()
-	if err1 != nil {
-		return body, err1
+
+	if res.StatusCode == http.StatusNotModified {
+		return Response{NotModified: true, ETag: etag, LastModified: lastModified}, nil
+	}
+	if res.StatusCode > 299 {
+		return Response{}, &StatusError{
+			URL:        url,
+			StatusCode: res.StatusCode,
+			kind:       statusKind(res.StatusCode),
+			retryAfter: parseRetryAfter(res.Header.Get("Retry-After")),
+		}
+	}
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return Response{}, err
+	}
+	return Response{
+		Body:         body,
+		ETag:         res.Header.Get("ETag"),
+		LastModified: res.Header.Get("Last-Modified"),
+	}, nil
+}
+
+func statusKind(code int) error {
+	switch {
+	case code == http.StatusNotFound:
+		return ErrNotFound
+	case code == http.StatusTooManyRequests:
+		return ErrRateLimited
+	default:
+		return ErrUpstream
+	}
+}
+
+// retryable reports whether a failed attempt is worth another try. Only the
+// caller's context ends the retries, a request running past the client
+// timeout is retried like any other transient failure.
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= 500
+	}
+	// Only connection errors, timeouts and connections dropped mid-response
+	// are worth another try. A url.Error is itself a net.Error, so look at
+	// what it wraps: a bad URL or scheme fails the same way every time.
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// backoff is the wait before the next attempt, and false when the server
+// asks for a longer wait than maxRetryAfter.
+func (c Client) backoff(attempt int, lastErr error) (time.Duration, bool) {
+	var statusErr *StatusError
+	if errors.As(lastErr, &statusErr) && statusErr.retryAfter > 0 {
+		return statusErr.retryAfter, statusErr.retryAfter <= c.maxRetryAfter
+	}
+	delay := c.baseDelay << (attempt - 1)
+	if delay > c.maxDelay || delay <= 0 {
+		delay = c.maxDelay
+	}
+	// Full jitter keeps concurrent clients from retrying in lockstep.
+	return time.Duration(rand.Int63n(int64(delay) + 1)), true
+}
+
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0)
 	}
-	return body, nil
+	return 0
 }
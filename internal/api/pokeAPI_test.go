@@ -0,0 +1,215 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testClient() Client {
+	client := NewClient(DefaultBaseURL, time.Second)
+	client.baseDelay = time.Millisecond
+	client.maxDelay = 5 * time.Millisecond
+	return client
+}
+
+func TestGetRetriesServerErrors(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("testdata"))
+	}))
+	defer server.Close()
+
+	body, err := testClient().Get(context.Background(), server.URL)
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+		return
+	}
+	if string(body) != "testdata" {
+		t.Errorf("expected to find value")
+	}
+	if calls != 3 {
+		t.Errorf("expected 3 calls, got %v", calls)
+	}
+}
+
+func TestGetTypedErrors(t *testing.T) {
+	cases := []struct {
+		status    int
+		expected  error
+		wantCalls int
+	}{
+		{status: http.StatusNotFound, expected: ErrNotFound, wantCalls: 1},
+		{status: http.StatusTooManyRequests, expected: ErrRateLimited, wantCalls: 4},
+		{status: http.StatusInternalServerError, expected: ErrUpstream, wantCalls: 4},
+	}
+
+	for _, c := range cases {
+		t.Run(http.StatusText(c.status), func(t *testing.T) {
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(c.status)
+			}))
+			defer server.Close()
+
+			_, err := testClient().Get(context.Background(), server.URL)
+			if !errors.Is(err, c.expected) {
+				t.Errorf("expected %v, got %v", c.expected, err)
+			}
+			if calls != c.wantCalls {
+				t.Errorf("expected %v calls, got %v", c.wantCalls, calls)
+			}
+		})
+	}
+}
+
+func TestGetHonorsRetryAfter(t *testing.T) {
+	cases := []struct {
+		retryAfter string
+		wantCalls  int
+		minElapsed time.Duration
+	}{
+		{retryAfter: "1", wantCalls: 2, minElapsed: time.Second},
+		{retryAfter: "60", wantCalls: 1},
+	}
+
+	for _, c := range cases {
+		t.Run(c.retryAfter, func(t *testing.T) {
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if calls == 1 {
+					w.Header().Set("Retry-After", c.retryAfter)
+					w.WriteHeader(http.StatusTooManyRequests)
+					return
+				}
+				w.Write([]byte("testdata"))
+			}))
+			defer server.Close()
+
+			client := testClient()
+			client.maxRetryAfter = 2 * time.Second
+			start := time.Now()
+			_, err := client.Get(context.Background(), server.URL)
+			if calls != c.wantCalls {
+				t.Errorf("expected %v calls, got %v", c.wantCalls, calls)
+			}
+			if c.wantCalls == 1 && !errors.Is(err, ErrRateLimited) {
+				t.Errorf("expected ErrRateLimited, got %v", err)
+			}
+			if elapsed := time.Since(start); elapsed < c.minElapsed {
+				t.Errorf("expected to wait %v, waited %v", c.minElapsed, elapsed)
+			}
+		})
+	}
+}
+
+type failingTransport struct {
+	err   error
+	calls int
+}
+
+func (f *failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	f.calls++
+	return nil, f.err
+}
+
+func TestGetRetriesOnlyTransportErrors(t *testing.T) {
+	cases := []struct {
+		err       error
+		wantCalls int
+	}{
+		{err: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}, wantCalls: 4},
+		{err: io.ErrUnexpectedEOF, wantCalls: 4},
+		{err: errors.New("unsupported protocol scheme"), wantCalls: 1},
+	}
+
+	for _, c := range cases {
+		t.Run(c.err.Error(), func(t *testing.T) {
+			transport := &failingTransport{err: c.err}
+			if _, err := testClient().WithTransport(transport).Get(context.Background(), DefaultBaseURL); err == nil {
+				t.Errorf("expected an error")
+			}
+			if transport.calls != c.wantCalls {
+				t.Errorf("expected %v calls, got %v", c.wantCalls, transport.calls)
+			}
+		})
+	}
+}
+
+func TestGetRetriesClientTimeouts(t *testing.T) {
+	calls := atomic.Int32{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			time.Sleep(200 * time.Millisecond)
+		}
+		w.Write([]byte("testdata"))
+	}))
+	defer server.Close()
+
+	client := testClient()
+	client.httpClient.Timeout = 50 * time.Millisecond
+	data, err := client.Get(context.Background(), server.URL)
+	if err != nil || string(data) != "testdata" {
+		t.Errorf("expected testdata, got %q, %v", data, err)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("expected 2 calls, got %v", got)
+	}
+}
+
+func TestGetStopsWhenCallerGivesUp(t *testing.T) {
+	transport := &failingTransport{err: io.ErrUnexpectedEOF}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := testClient().WithTransport(transport).Get(ctx, DefaultBaseURL); err == nil {
+		t.Errorf("expected an error")
+	}
+	if transport.calls != 1 {
+		t.Errorf("expected 1 call, got %v", transport.calls)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if got := parseRetryAfter("2"); got != 2*time.Second {
+		t.Errorf("expected 2s, got %v", got)
+	}
+	if got := parseRetryAfter("garbage"); got != 0 {
+		t.Errorf("expected 0, got %v", got)
+	}
+}
+
+func TestGetConditionalNotModified(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte("testdata"))
+	}))
+	defer server.Close()
+
+	first, err := testClient().GetConditional(context.Background(), server.URL, "", "")
+	if err != nil || first.NotModified || first.ETag != `"v1"` {
+		t.Errorf("expected a full response with an ETag, got %+v, %v", first, err)
+		return
+	}
+	second, err := testClient().GetConditional(context.Background(), server.URL, first.ETag, "")
+	if err != nil || !second.NotModified {
+		t.Errorf("expected a not modified response, got %+v, %v", second, err)
+	}
+}
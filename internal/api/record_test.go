@@ -0,0 +1,73 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFixturePath(t *testing.T) {
+	a, _ := url.Parse("https://pokeapi.co/api/v2/location-area/?offset=20&limit=20")
+	b, _ := url.Parse("http://127.0.0.1:8000/api/v2/location-area?limit=20&offset=20")
+	if FixturePath("dir", a) != FixturePath("dir", b) {
+		t.Errorf("expected %v and %v to share a fixture", a, b)
+	}
+	c, _ := url.Parse("https://pokeapi.co/api/v2/pokemon/25/encounters")
+	if path := FixturePath("dir", c); path != filepath.Join("dir", "api", "v2", "pokemon", "25", "encounters.json") {
+		t.Errorf("expected the fixture to follow the URL path, got %v", path)
+	}
+}
+
+func TestRecordThenReplay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v2/pokemon/missingno/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(`{"name":"pikachu"}`))
+	}))
+	dir := t.TempDir()
+	recording := testClient().WithTransport(Recorder{Dir: dir})
+	pikachu := server.URL + "/api/v2/pokemon/pikachu/"
+	missing := server.URL + "/api/v2/pokemon/missingno/"
+	if _, err := recording.Get(context.Background(), pikachu); err != nil {
+		t.Errorf("expected no error while recording, got %v", err)
+	}
+	if _, err := recording.Get(context.Background(), missing); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected the 404 to go through the recorder, got %v", err)
+	}
+	server.Close()
+
+	replaying := testClient().WithTransport(Replayer{Dir: dir})
+	body, err := replaying.Get(context.Background(), pikachu)
+	if err != nil || string(body) != `{"name":"pikachu"}` {
+		t.Errorf("expected the recorded body, got %q and %v", body, err)
+	}
+	if _, err := replaying.Get(context.Background(), missing); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected the recorded 404, got %v", err)
+	}
+	res, err := replaying.GetConditional(context.Background(), pikachu, `"v1"`, "")
+	if err != nil || !res.NotModified {
+		t.Errorf("expected the recorded ETag to revalidate, got %v", err)
+	}
+}
+
+func TestReplayFailsOnUnrecordedRequests(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "broken.json"), []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	client := testClient().WithTransport(Replayer{Dir: dir})
+	if _, err := client.Get(context.Background(), "https://pokeapi.co/api/v2/pokemon/pikachu/"); !errors.Is(err, ErrUnrecorded) {
+		t.Errorf("expected ErrUnrecorded, got %v", err)
+	}
+	if _, err := client.Get(context.Background(), "https://pokeapi.co/broken"); err == nil || errors.Is(err, ErrUnrecorded) {
+		t.Errorf("expected an invalid fixture error, got %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const DefaultBaseURL = "https://pokeapi.co/api/v2/"
+
+func (c Client) ResourceURL(kind, nameOrID string) string {
+	return c.baseURL + kind + "/" + strings.ToLower(nameOrID) + "/"
+}
+
+// ParseResourceURL extracts the kind and numeric id from links such as
+// https://pokeapi.co/api/v2/location-area/1/ found in list responses.
+func ParseResourceURL(rawURL string) (string, int, bool) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "", 0, false
+	}
+	parts := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+	if len(parts) < 2 {
+		return "", 0, false
+	}
+	id, err := strconv.Atoi(parts[len(parts)-1])
+	if err != nil {
+		return "", 0, false
+	}
+	return parts[len(parts)-2], id, true
+}
+
+func (c Client) PageURL(kind string, offset, limit int) string {
+	return fmt.Sprintf("%v%v/?offset=%d&limit=%d", c.baseURL, kind, offset, limit)
+}
+
+// ParsePage reads the offset and limit of a list link, defaulting to the
+// first page of 20 like PokeAPI does.
+func ParsePage(rawURL string) (int, int) {
+	offset, limit := 0, 20
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return offset, limit
+	}
+	query := parsed.Query()
+	if value, err := strconv.Atoi(query.Get("offset")); err == nil {
+		offset = value
+	}
+	if value, err := strconv.Atoi(query.Get("limit")); err == nil {
+		limit = value
+	}
+	return offset, limit
+}
+
+// PageKey identifies a list page by its kind, offset and limit, whatever the
+// order of the query parameters or the trailing slash.
+func PageKey(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	parts := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+	offset, limit := ParsePage(rawURL)
+	return fmt.Sprintf("%v?offset=%d&limit=%d", parts[len(parts)-1], offset, limit)
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestParseResourceURL(t *testing.T) {
+	cases := []struct {
+		url  string
+		kind string
+		id   int
+		ok   bool
+	}{
+		{url: "https://pokeapi.co/api/v2/location-area/1/", kind: "location-area", id: 1, ok: true},
+		{url: "https://pokeapi.co/api/v2/pokemon/25", kind: "pokemon", id: 25, ok: true},
+		{url: "https://pokeapi.co/api/v2/pokemon/pikachu/", ok: false},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("Test case %v", i), func(t *testing.T) {
+			kind, id, ok := ParseResourceURL(c.url)
+			if ok != c.ok || kind != c.kind || id != c.id {
+				t.Errorf("expected (%v, %v, %v), got (%v, %v, %v)", c.kind, c.id, c.ok, kind, id, ok)
+			}
+		})
+	}
+}
+
+func TestPageKey(t *testing.T) {
+	a := PageKey("https://pokeapi.co/api/v2/location-area/?offset=20&limit=20")
+	b := PageKey("https://pokeapi.co/api/v2/location-area?limit=20&offset=20")
+	if a != b {
+		t.Errorf("expected %v and %v to match", a, b)
+	}
+}
+
+func TestParsePage(t *testing.T) {
+	offset, limit := ParsePage(NewClient(DefaultBaseURL, time.Second).PageURL("location-area", 40, 10))
+	if offset != 40 || limit != 10 {
+		t.Errorf("expected (40, 10), got (%v, %v)", offset, limit)
+	}
+	offset, limit = ParsePage("https://pokeapi.co/api/v2/location-area/")
+	if offset != 0 || limit != 20 {
+		t.Errorf("expected defaults (0, 20), got (%v, %v)", offset, limit)
+	}
+}
+
+func TestClientURLsUseBaseURL(t *testing.T) {
+	client := NewClient("http://localhost:8000/api/v2/", time.Second)
+	if url := client.ResourceURL("pokemon", "Pikachu"); url != "http://localhost:8000/api/v2/pokemon/pikachu/" {
+		t.Errorf("expected resource url on the mirror, got %v", url)
+	}
+	if url := client.PageURL("region", 0, 100); url != "http://localhost:8000/api/v2/region/?offset=0&limit=100" {
+		t.Errorf("expected page url on the mirror, got %v", url)
+	}
+}
@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// ErrExit is returned by the exit command, the caller decides how to stop.
+var ErrExit = errors.New("Exit the Pokedex")
+
+// Command is one REPL command. Run renders its result to w and reads the
+// arguments that follow the command name.
+type Command interface {
+	Name() string
+	Description() string
+	Usage() string
+	Run(ctx context.Context, w io.Writer, args []string) error
+}
+
+type command struct {
+	name        string
+	description string
+	usage       string
+	run         func(ctx context.Context, w io.Writer, args []string) error
+}
+
+func (c command) Name() string {
+	return c.name
+}
+
+func (c command) Description() string {
+	return c.description
+}
+
+func (c command) Usage() string {
+	if c.usage == "" {
+		return c.name
+	}
+	return c.usage
+}
+
+func (c command) Run(ctx context.Context, w io.Writer, args []string) error {
+	return c.run(ctx, w, args)
+}
+
+type UnknownCommandError struct {
+	Name string
+}
+
+func (e UnknownCommandError) Error() string {
+	return fmt.Sprintf("Unknown command %q", e.Name)
+}
+
+type Registry struct {
+	commands map[string]Command
+}
+
+func NewRegistry() Registry {
+	return Registry{commands: map[string]Command{}}
+}
+
+// Register adds a command, replacing any command with the same name.
+func (r Registry) Register(c Command) {
+	r.commands[c.Name()] = c
+}
+
+func (r Registry) Lookup(name string) (Command, bool) {
+	c, ok := r.commands[name]
+	return c, ok
+}
+
+// Commands lists the registered commands sorted by name.
+func (r Registry) Commands() []Command {
+	commands := make([]Command, 0, len(r.commands))
+	for _, c := range r.commands {
+		commands = append(commands, c)
+	}
+	sort.Slice(commands, func(i, j int) bool { return commands[i].Name() < commands[j].Name() })
+	return commands
+}
@@ -0,0 +1,613 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/c00rni/pokedex/internal/api"
+	"github.com/c00rni/pokedex/internal/journal"
+	"github.com/c00rni/pokedex/internal/search"
+)
+
+func (s *Session) builtins() []Command {
+	return []Command{
+		command{name: "help", description: "Displays a help message", usage: "help [command]", run: s.commandHelp},
+		command{name: "exit", description: "Exit the Pokedex", run: s.commandExit},
+		command{name: "map", description: "Discover new areas", usage: "map [first|last] [--page N] [--limit N]", run: s.commandMap},
+		command{name: "mapb", description: "Diplay previous areas", run: s.commandMapB},
+		command{name: "explore", description: "List pokemons in an area", usage: "explore <area>", run: s.commandExplore},
+		command{name: "regions", description: "List the regions of the Pokemon world", run: s.commandRegions},
+		command{name: "locations", description: "List the locations of a region", usage: "locations <region>", run: s.commandLocations},
+		command{name: "areas", description: "List the areas of a location", usage: "areas <location>", run: s.commandAreas},
+		command{name: "search", description: "Search resources by name", usage: "search <query> [--kind pokemon|area|move|item]", run: s.commandSearch},
+		command{name: "where", description: "List the areas where a pokemon can be found", usage: "where <pokemon> [--version x]", run: s.commandWhere},
+		command{name: "dex", description: "Show Pokedex completion", usage: "dex [--summary] [--region name [--where]]", run: s.commandDex},
+		command{name: "journal", description: "Show past catches and explorations", usage: "journal [--since 1h] [--kind catch|explore]", run: s.commandJournal},
+		command{name: "stats", description: "Summarize catch success rates per species and per ball", run: s.commandStats},
+		command{name: "source", description: "Run the commands of a script file, one per line", usage: "source <file>", run: s.commandSource},
+		command{name: "catch", description: "Attempt to capture a pokemon", usage: "catch <pokemon>", run: s.commandCatch},
+		command{name: "inspect", description: "Print stats about a pokemon", usage: "inspect <pokemon>", run: s.commandInspect},
+		command{name: "pokedex", description: "Print the captured pokemon", usage: "pokedex [--sort name|id|caught|bst] [--type t] [--gen n] [--min-bst n]", run: s.commandPokedex},
+		command{name: "trainer", description: "Show, create, switch or list trainers", usage: "trainer [list | new <name> | switch <name>]", run: s.commandTrainer},
+		command{name: "serve", description: "Serve the areas and the pokedex as a JSON API", usage: "serve [--addr :8080]", run: s.commandServe},
+		command{name: "config", description: "Show or change the settings", usage: "config [get <name> | set <name> <value>]", run: s.commandConfig},
+		command{name: "cache", description: "Show cache statistics or drop entries", usage: "cache stats | cache clear [prefix]", run: s.commandCache},
+	}
+}
+
+func (s *Session) commandHelp(_ context.Context, w io.Writer, args []string) error {
+	result := helpResult{Commands: []helpEntry{}}
+	for _, c := range s.registry.Commands() {
+		if len(args) > 0 && c.Name() != args[0] {
+			continue
+		}
+		result.Commands = append(result.Commands, helpEntry{Name: c.Name(), Usage: c.Usage(), Description: c.Description()})
+	}
+	if len(args) > 0 && len(result.Commands) == 0 {
+		return UnknownCommandError{Name: args[0]}
+	}
+	return s.render(w, result)
+}
+
+func (s *Session) commandExit(_ context.Context, _ io.Writer, _ []string) error {
+	return ErrExit
+}
+
+func (s *Session) lookupPokemon(ctx context.Context, name string) (pokemon, error) {
+	pokemonDetails, err := lookupResource(ctx, s, s.pokemons, name)
+	if errors.Is(err, api.ErrNotFound) {
+		return pokemonDetails, s.unknownPokemon(ctx, name)
+	}
+	return pokemonDetails, err
+}
+
+var errAlreadyCaught = errors.New("Pokemon already captured.")
+
+// caught finds a pokemon of the pokedex by any of its spellings, pikachu,
+// Pikachu or 25, since the pokedex is keyed by the API name.
+func (s *Session) caught(ctx context.Context, name string) (caughtPokemon, bool, error) {
+	if c, ok := s.pokedex[name]; ok {
+		return c, true, nil
+	}
+	pokemonDetails, err := s.lookupPokemon(ctx, name)
+	if err != nil {
+		return caughtPokemon{}, false, err
+	}
+	c, ok := s.pokedex[pokemonDetails.Name]
+	return c, ok, nil
+}
+
+func (s *Session) catch(ctx context.Context, name string) (catchResult, error) {
+	pokemonDetails, err := s.lookupPokemon(ctx, name)
+	if err != nil {
+		return catchResult{}, err
+	}
+	if _, ok := s.pokedex[pokemonDetails.Name]; ok {
+		return catchResult{}, errAlreadyCaught
+	}
+
+	if number, ok := nationalNumber(pokemonDetails.Species.URL); ok {
+		s.seen[number] = true
+	}
+	entry := journal.Entry{Kind: journal.KindCatch, Pokemon: pokemonDetails.Name, Area: s.state.Area, Ball: "poke-ball"}
+	if float64(pokemonDetails.BaseExperience)*s.random() < s.config.CatchThreshold {
+		s.pokedex[pokemonDetails.Name] = caughtPokemon{pokemon: pokemonDetails, CaughtAt: s.now()}
+		entry.Outcome = journal.OutcomeCaught
+	} else {
+		entry.Outcome = journal.OutcomeEscaped
+	}
+	s.record(entry)
+	s.saveTrainer()
+	return catchResult{Pokemon: pokemonDetails.Name, Outcome: entry.Outcome}, nil
+}
+
+func (s *Session) commandCatch(ctx context.Context, w io.Writer, args []string) error {
+	if len(args) < 1 {
+		return errors.New("The catch command need a pokemon name as argument")
+	}
+	result, err := s.catch(ctx, args[0])
+	if errors.Is(err, errAlreadyCaught) {
+		return s.render(w, message{Message: err.Error()})
+	}
+	if err != nil {
+		return err
+	}
+	return s.render(w, result)
+}
+
+func (s *Session) commandInspect(ctx context.Context, w io.Writer, args []string) error {
+	if len(args) < 1 {
+		return errors.New("The catch command need a pokemon name as argument")
+	}
+	pokemonDetails, ok, err := s.caught(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return s.render(w, message{Message: "you have not caught that pokemon"})
+	}
+	return s.render(w, newInspectResult(pokemonDetails.pokemon))
+}
+
+func (s *Session) commandPokedex(ctx context.Context, w io.Writer, args []string) error {
+	query, err := parsePokedexQuery(args)
+	if err != nil {
+		return err
+	}
+	result, err := s.listPokedex(ctx, query)
+	if err != nil {
+		return err
+	}
+	return s.render(w, result)
+}
+
+func (s *Session) listPokedex(ctx context.Context, query pokedexQuery) (pokedexResult, error) {
+	caught := make([]caughtPokemon, 0, len(s.pokedex))
+	for _, c := range s.pokedex {
+		caught = append(caught, c)
+	}
+	shown, err := query.filter(caught, s.generationOf(ctx))
+	if err != nil {
+		return pokedexResult{}, err
+	}
+	query.sort(shown)
+
+	result := pokedexResult{Pokemon: []pokedexRow{}, Shown: len(shown), Caught: len(s.pokedex), Seen: len(s.seen)}
+	for _, c := range shown {
+		result.Pokemon = append(result.Pokemon, pokedexRow{
+			Name:     c.Name,
+			ID:       c.ID,
+			Types:    newInspectResult(c.pokemon).Types,
+			BST:      c.baseStatTotal(),
+			CaughtAt: c.CaughtAt,
+		})
+	}
+	return result, nil
+}
+
+func (s *Session) commandJournal(_ context.Context, w io.Writer, args []string) error {
+	filter := journal.Filter{}
+	for i := 0; i < len(args); i += 2 {
+		if i+1 >= len(args) {
+			return fmt.Errorf("The %v option needs a value", args[i])
+		}
+		switch args[i] {
+		case "--since":
+			since, err := time.ParseDuration(args[i+1])
+			if err != nil {
+				return fmt.Errorf("The --since option needs a duration such as 1h, got %q", args[i+1])
+			}
+			filter.Since = s.now().Add(-since)
+		case "--kind":
+			filter.Kind = args[i+1]
+		default:
+			return fmt.Errorf("Unknown journal option %q", args[i])
+		}
+	}
+	entries, err := s.journal.Read(filter)
+	if err != nil {
+		return err
+	}
+	return s.render(w, journalResult{Entries: entries})
+}
+
+func (s *Session) commandStats(_ context.Context, w io.Writer, _ []string) error {
+	entries, err := s.journal.Read(journal.Filter{Kind: journal.KindCatch})
+	if err != nil {
+		return err
+	}
+	return s.render(w, statsResult{
+		PerSpecies: journal.CatchRates(entries, func(e journal.Entry) string { return e.Pokemon }),
+		PerBall:    journal.CatchRates(entries, func(e journal.Entry) string { return e.Ball }),
+	})
+}
+
+func (s *Session) commandDex(ctx context.Context, w io.Writer, args []string) error {
+	summaryOnly, withPlaces, regionName := false, false, ""
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--summary":
+			summaryOnly = true
+		case args[i] == "--where":
+			withPlaces = true
+		case args[i] == "--region" && i+1 < len(args):
+			regionName = args[i+1]
+			i++
+		default:
+			return errors.New("Usage: dex [--summary] [--region name [--where]]")
+		}
+	}
+	caught := s.caughtNumbers()
+
+	if regionName != "" {
+		// Accept a pokedex name such as original-johto as well as a region.
+		dex, err := lookupResource(ctx, s, s.regionalDexes, regionName)
+		if errors.Is(err, api.ErrNotFound) {
+			regionDetails, regionErr := lookupResource(ctx, s, s.regions, regionName)
+			if regionErr != nil {
+				return regionErr
+			}
+			if len(regionDetails.Pokedexes) == 0 {
+				return fmt.Errorf("The %v region has no pokedex", regionDetails.Name)
+			}
+			dex, err = lookupResource(ctx, s, s.regionalDexes, regionDetails.Pokedexes[0].Name)
+		}
+		if err != nil {
+			return err
+		}
+		var whereOf func(int) ([]string, error)
+		if withPlaces {
+			whereOf = func(number int) ([]string, error) {
+				target := s.client.ResourceURL("pokemon", strconv.Itoa(number)) + "encounters"
+				found, err := lookup(ctx, s, s.encounters, strconv.Itoa(number), target)
+				if err != nil {
+					return nil, err
+				}
+				places := []string{}
+				for _, place := range found {
+					places = append(places, place.LocationArea.Name)
+				}
+				return places, nil
+			}
+		}
+		result, err := newRegionalDexResult(dex, caught, summaryOnly, whereOf)
+		if err != nil {
+			return err
+		}
+		return s.render(w, result)
+	}
+
+	result := nationalDexResult{Seen: len(s.seen), Caught: len(caught)}
+	if !summaryOnly {
+		list, err := s.page(ctx, s.client.PageURL("pokemon-species", 0, 100000))
+		if err != nil {
+			return err
+		}
+		result.Entries = dexRows(list, s.seen, caught)
+	}
+
+	list, err := s.page(ctx, s.client.PageURL("generation", 0, 100))
+	if err != nil {
+		return err
+	}
+	all := []generation{}
+	for _, entry := range list.Results {
+		g, err := lookupResource(ctx, s, s.generations, entry.Name)
+		if err != nil {
+			return err
+		}
+		all = append(all, g)
+		result.Total += len(g.PokemonSpecies)
+	}
+	result.Generations = generationCompletions(all, s.seen, caught)
+	return s.render(w, result)
+}
+
+func (s *Session) commandExplore(ctx context.Context, w io.Writer, args []string) error {
+	if len(args) < 1 {
+		return errors.New("The explore command needs one area name")
+	}
+	result, err := s.explore(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	return s.render(w, result)
+}
+
+func (s *Session) explore(ctx context.Context, name string) (exploreResult, error) {
+	areaDetails, err := lookupResource(ctx, s, s.areas, name)
+	if err != nil {
+		return exploreResult{}, err
+	}
+	localized := []string{}
+	for _, name := range areaDetails.Names {
+		localized = append(localized, name.Name)
+	}
+	s.index.Add(s.areas.Kind(), areaDetails.Name, localized...)
+	// The breadcrumb is a nicety, an area still gets explored without it.
+	regionName := ""
+	if parent, err := lookupResource(ctx, s, s.locations, areaDetails.Location.Name); err == nil {
+		regionName = parent.Region.Name
+	}
+
+	for _, data := range areaDetails.PokemonEncounters {
+		if number, ok := nationalNumber(data.Pokemon.URL); ok {
+			s.seen[number] = true
+		}
+	}
+	s.state.Region = regionName
+	s.state.Area = areaDetails.Name
+	s.record(journal.Entry{Kind: journal.KindExplore, Area: areaDetails.Name})
+	s.state.Location = areaDetails.Location.Name
+	s.saveTrainer()
+
+	result := exploreResult{Region: regionName, Location: areaDetails.Location.Name, Area: areaDetails.Name, Pokemon: []string{}}
+	for _, data := range areaDetails.PokemonEncounters {
+		result.Pokemon = append(result.Pokemon, data.Pokemon.Name)
+	}
+	return result, nil
+}
+
+func (s *Session) commandWhere(ctx context.Context, w io.Writer, args []string) error {
+	if len(args) < 1 {
+		return errors.New("The where command needs a pokemon name as argument")
+	}
+	version := ""
+	if len(args) > 2 && args[1] == "--version" {
+		version = args[2]
+	} else if len(args) > 1 {
+		return errors.New("Usage: where <pokemon> [--version x]")
+	}
+	pokemonDetails, err := s.lookupPokemon(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	found, err := lookup(ctx, s, s.encounters, strconv.Itoa(pokemonDetails.ID), pokemonDetails.LocationAreaEncounters)
+	if err != nil {
+		return err
+	}
+	// Sort a copy, the cached slice is shared between lookups.
+	found = append([]encounter{}, found...)
+
+	// Area names are their location name, or that name followed by a dash
+	// and a suffix, which is enough to tell whether an area sits in the
+	// current location or region without fetching every area.
+	locations := []string{}
+	if s.state.Region != "" {
+		if current, err := lookupResource(ctx, s, s.regions, s.state.Region); err == nil {
+			for _, result := range current.Locations {
+				locations = append(locations, result.Name)
+			}
+		}
+	}
+	locations = append(locations, s.state.Location)
+	distance := func(areaName string) int {
+		parent := parentLocation(areaName, locations)
+		switch {
+		case parent != "" && parent == s.state.Location:
+			return 0
+		case parent != "":
+			return 1
+		default:
+			return 2
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		a, b := found[i].LocationArea.Name, found[j].LocationArea.Name
+		if distance(a) != distance(b) {
+			return distance(a) < distance(b)
+		}
+		return a < b
+	})
+
+	return s.render(w, newWhereResult(pokemonDetails.Name, version, found))
+}
+
+// parentLocation picks the longest of the location names the area belongs
+// to, so route-10-area goes to route-10 rather than route-1.
+func parentLocation(areaName string, locations []string) string {
+	parent := ""
+	for _, name := range locations {
+		if name == "" || len(name) <= len(parent) {
+			continue
+		}
+		if areaName == name || strings.HasPrefix(areaName, name+"-") {
+			parent = name
+		}
+	}
+	return parent
+}
+
+func (s *Session) commandSearch(ctx context.Context, w io.Writer, args []string) error {
+	query, kinds := "", []string{}
+	for i := 0; i < len(args); i++ {
+		if args[i] != "--kind" {
+			query = strings.TrimSpace(query + " " + args[i])
+			continue
+		}
+		if i+1 >= len(args) {
+			return errors.New("The --kind option needs one of pokemon, area, move or item")
+		}
+		kind, ok := searchKinds[args[i+1]]
+		if !ok {
+			return fmt.Errorf("Unknown kind %q, expected one of pokemon, area, move or item", args[i+1])
+		}
+		kinds = append(kinds, kind)
+		i++
+	}
+	if query == "" {
+		return errors.New("The search command needs a query")
+	}
+	if len(kinds) == 0 {
+		kinds = []string{"pokemon", "location-area", "move", "item"}
+	}
+
+	results := []search.Result{}
+	for _, kind := range kinds {
+		if err := s.ensureIndexed(ctx, kind); err != nil {
+			return err
+		}
+		results = append(results, s.index.Search(query, kind, 10)...)
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	result := searchResult{Query: query, Results: []searchHit{}}
+	for i := 0; i < len(results) && i < 10; i++ {
+		result.Results = append(result.Results, searchHit{Name: results[i].Name, Kind: results[i].Kind, Score: results[i].Score})
+	}
+	return s.render(w, result)
+}
+
+func (s *Session) commandRegions(ctx context.Context, w io.Writer, _ []string) error {
+	response, err := s.page(ctx, s.client.PageURL("region", 0, 100))
+	if err != nil {
+		return err
+	}
+	learnAliases(s.aliases, response)
+	result := listing{Title: "Regions:", Items: []string{}}
+	for _, entry := range response.Results {
+		result.Items = append(result.Items, entry.Name)
+	}
+	return s.render(w, result)
+}
+
+func (s *Session) commandLocations(ctx context.Context, w io.Writer, args []string) error {
+	if len(args) < 1 {
+		return errors.New("The locations command needs one region name")
+	}
+	regionDetails, err := lookupResource(ctx, s, s.regions, args[0])
+	if err != nil {
+		return err
+	}
+	result := listing{Title: fmt.Sprintf("Locations in %v:", breadcrumb(regionDetails.Name)), Items: []string{}}
+	for _, entry := range regionDetails.Locations {
+		result.Items = append(result.Items, entry.Name)
+	}
+	return s.render(w, result)
+}
+
+func (s *Session) commandAreas(ctx context.Context, w io.Writer, args []string) error {
+	if len(args) < 1 {
+		return errors.New("The areas command needs one location name")
+	}
+	locationDetails, err := lookupResource(ctx, s, s.locations, args[0])
+	if err != nil {
+		return err
+	}
+	result := listing{Title: fmt.Sprintf("Areas in %v:", breadcrumb(locationDetails.Region.Name, locationDetails.Name)), Items: []string{}}
+	for _, entry := range locationDetails.Areas {
+		result.Items = append(result.Items, entry.Name)
+	}
+	return s.render(w, result)
+}
+
+type pageRangeError struct {
+	page  int
+	pages int
+}
+
+func (e pageRangeError) Error() string {
+	return fmt.Sprintf("Page %v is out of range, there are %v pages", e.page, e.pages)
+}
+
+func (s *Session) locationPage(ctx context.Context, target string) (locationPage, response, error) {
+	response, err := s.page(ctx, target)
+	if err != nil {
+		return locationPage{}, response, err
+	}
+	offset, limit := api.ParsePage(target)
+	if len(response.Results) == 0 && response.Count > 0 {
+		return locationPage{}, response, pageRangeError{page: offset/limit + 1, pages: pageCount(response.Count, limit)}
+	}
+	learnAliases(s.aliases, response)
+
+	page := locationPage{Page: offset/limit + 1, Pages: pageCount(response.Count, limit), Count: response.Count, Locations: []string{}}
+	for _, result := range response.Results {
+		page.Locations = append(page.Locations, result.Name)
+	}
+	return page, response, nil
+}
+
+func (s *Session) showPage(ctx context.Context, w io.Writer, target string) error {
+	page, response, err := s.locationPage(ctx, target)
+	if err != nil {
+		return err
+	}
+	s.state.Next = response.Next
+	s.state.Previous = response.Previous
+	s.state.Offset, s.state.Limit = api.ParsePage(target)
+	s.state.Count = response.Count
+	return s.render(w, page)
+}
+
+func (s *Session) commandMap(ctx context.Context, w io.Writer, args []string) error {
+	if len(args) == 0 {
+		if s.state.Next == "" {
+			return s.render(w, message{Message: "You are on the last page of locations."})
+		}
+		return s.showPage(ctx, w, s.state.Next)
+	}
+
+	offset, limit := s.state.Offset, s.state.Limit
+	page, last := 0, false
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "first":
+			page = 1
+		case "last":
+			last = true
+		case "--page", "--limit":
+			if i+1 >= len(args) {
+				return fmt.Errorf("The %v option needs a number", args[i])
+			}
+			value, err := strconv.Atoi(args[i+1])
+			if err != nil || value < 1 {
+				return fmt.Errorf("The %v option needs a positive number, got %q", args[i], args[i+1])
+			}
+			if args[i] == "--page" {
+				page = value
+			} else {
+				limit = value
+			}
+			i++
+		default:
+			return fmt.Errorf("Unknown map option %q", args[i])
+		}
+	}
+	if last {
+		if s.state.Count == 0 {
+			response, err := s.page(ctx, s.client.PageURL("location-area", 0, limit))
+			if err != nil {
+				return err
+			}
+			s.state.Count = response.Count
+		}
+		page = pageCount(s.state.Count, limit)
+	}
+	if page > 0 {
+		offset = (page - 1) * limit
+	}
+	return s.showPage(ctx, w, s.client.PageURL("location-area", offset, limit))
+}
+
+func (s *Session) commandMapB(ctx context.Context, w io.Writer, _ []string) error {
+	if s.state.Previous == "" {
+		return s.render(w, message{Message: "You are on the first page of locations."})
+	}
+	return s.showPage(ctx, w, s.state.Previous)
+}
+
+func (s *Session) commandCache(_ context.Context, w io.Writer, args []string) error {
+	if len(args) < 1 {
+		return errors.New("The cache command needs a subcommand: stats or clear [prefix]")
+	}
+	switch args[0] {
+	case "stats":
+		stats := s.cache.Stats()
+		return s.render(w, cacheStats{Entries: stats.Entries, Bytes: stats.Bytes, Hits: stats.Hits, Misses: stats.Misses, Evictions: stats.Evictions})
+	case "clear":
+		// Entries are keyed by kind and id, such as pokemon/25.
+		prefix := ""
+		if len(args) > 1 {
+			prefix = args[1]
+		}
+		removed := s.cache.Clear(prefix)
+		return s.render(w, message{Message: fmt.Sprintf("Removed %v cache entries.", removed)})
+	default:
+		return fmt.Errorf("Unknown cache subcommand %q", args[0])
+	}
+}
+
+func (s *Session) commandSource(ctx context.Context, w io.Writer, args []string) error {
+	if len(args) != 1 {
+		return errors.New("The source command needs one script file")
+	}
+	return s.source(ctx, w, args[0])
+}
@@ -0,0 +1,387 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/c00rni/pokedex/internal/api"
+	"github.com/c00rni/pokedex/internal/config"
+	"github.com/c00rni/pokedex/internal/fakeapi"
+	"github.com/c00rni/pokedex/internal/journal"
+	"github.com/c00rni/pokedex/internal/pokecache"
+	"github.com/c00rni/pokedex/internal/render"
+	"github.com/c00rni/pokedex/internal/trainer"
+)
+
+func newTestSession(t *testing.T) *Session {
+	t.Helper()
+	return newTestSessionIn(t, t.TempDir())
+}
+
+// newTestSessionIn keeps the trainers in dir, so a later session can resume
+// them.
+func newTestSessionIn(t *testing.T, dir string) *Session {
+	t.Helper()
+	server := fakeapi.NewServer()
+	t.Cleanup(server.Close)
+	cache := pokecache.NewCache(time.Minute)
+	t.Cleanup(cache.Close)
+	session, err := NewSession(Options{
+		Client:     api.NewClient(server.BaseURL(), time.Second),
+		Cache:      cache,
+		Config:     config.Default(),
+		ConfigPath: filepath.Join(dir, "config.toml"),
+		DataDir:    dir,
+		IndexPath:  filepath.Join(dir, "search.json"),
+		Format:     render.Text,
+		Random:     func() float64 { return 0 },
+		Now:        func() time.Time { return time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return session
+}
+
+func TestCommands(t *testing.T) {
+	cases := []struct {
+		lines    []string
+		expected string
+		err      string
+	}{
+		{
+			lines:    []string{"map"},
+			expected: "Page 1 of 1\ncanalave-city-area\neterna-city-area\npastoria-city-area\nsunyshore-city-area\nsinnoh-pokemon-league-area\n",
+		},
+		{
+			lines:    []string{"map --limit 4", "map"},
+			expected: "Page 1 of 2\ncanalave-city-area\neterna-city-area\npastoria-city-area\nsunyshore-city-area\nPage 2 of 2\nsinnoh-pokemon-league-area\n",
+		},
+		{
+			lines:    []string{"map --limit 4", "map", "map"},
+			expected: "Page 1 of 2\ncanalave-city-area\neterna-city-area\npastoria-city-area\nsunyshore-city-area\nPage 2 of 2\nsinnoh-pokemon-league-area\nYou are on the last page of locations.\n",
+		},
+		{
+			lines:    []string{"mapb"},
+			expected: "You are on the first page of locations.\n",
+		},
+		{
+			lines:    []string{"explore canalave-city-area"},
+			expected: "Exploring sinnoh > canalave-city > canalave-city-area...\nFound Pokemon:\n - tentacool\n - magikarp\n",
+		},
+		{
+			lines:    []string{"catch pikachu"},
+			expected: "Throwing a Pokeball at pikachu...\npikachu was caught!\n",
+		},
+		{
+			lines:    []string{"catch pikachu", "pokedex"},
+			expected: "Throwing a Pokeball at pikachu...\npikachu was caught!\nYour Pokedex:\n - pikachu\n1 caught / 1 seen\n",
+		},
+		{
+			lines:    []string{"catch pikachu", "inspect pikachu"},
+			expected: "Throwing a Pokeball at pikachu...\npikachu was caught!\nName: pikachu\nHeight: 4\nWeight: 60\nStats:\n - hp: 35\n - attack: 55\n - defense: 40\n - special-attack: 50\n - special-defense: 50\n - speed: 90\nTypes:\n - electric\n",
+		},
+		{
+			lines:    []string{"explore canalave-city-area", "search eterna --kind area"},
+			expected: "Exploring sinnoh > canalave-city > canalave-city-area...\nFound Pokemon:\n - tentacool\n - magikarp\nResults:\n - eterna-city-area (location-area)\n",
+		},
+		{
+			lines:    []string{"catch 25", "catch Pikachu", "inspect pikachu", "pokedex"},
+			expected: "Throwing a Pokeball at pikachu...\npikachu was caught!\nPokemon already captured.\nName: pikachu\nHeight: 4\nWeight: 60\nStats:\n - hp: 35\n - attack: 55\n - defense: 40\n - special-attack: 50\n - special-defense: 50\n - speed: 90\nTypes:\n - electric\nYour Pokedex:\n - pikachu\n1 caught / 1 seen\n",
+		},
+		{
+			lines:    []string{"inspect pikachu"},
+			expected: "you have not caught that pokemon\n",
+		},
+		{
+			lines: []string{"catch pikachoo"},
+			err:   `Unknown pokemon "pikachoo". Did you mean: pikachu?`,
+		},
+		{
+			lines: []string{"catch"},
+			err:   "The catch command need a pokemon name as argument",
+		},
+		{
+			lines:    []string{"help catch"},
+			expected: "Welcome to the Pokedex!\nUsage:\ncatch: Attempt to capture a pokemon (catch <pokemon>)\n",
+		},
+		{
+			lines: []string{"fly"},
+			err:   `Unknown command "fly"`,
+		},
+		{
+			lines:    []string{"map --limit 2 --output json"},
+			expected: "{\n  \"page\": 1,\n  \"pages\": 3,\n  \"count\": 5,\n  \"locations\": [\n    \"canalave-city-area\",\n    \"eterna-city-area\"\n  ]\n}\n",
+		},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("Test case %v", i), func(t *testing.T) {
+			session := newTestSession(t)
+			out := &bytes.Buffer{}
+			var err error
+			for _, line := range c.lines {
+				if err = session.ExecLine(context.Background(), out, line); err != nil {
+					break
+				}
+			}
+			if c.err != "" {
+				if err == nil || err.Error() != c.err {
+					t.Errorf("expected error %q, got %v", c.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("expected no error, got %v", err)
+				return
+			}
+			if out.String() != c.expected {
+				t.Errorf("expected %q, got %q", c.expected, out.String())
+			}
+		})
+	}
+}
+
+func TestTrainersPersist(t *testing.T) {
+	dir := t.TempDir()
+	legacy := `{"time":"2024-04-01T00:00:00Z","kind":"explore","area":"eterna-city-area"}` + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "journal.jsonl"), []byte(legacy), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	script := "explore pastoria-city-area\ncatch buizel\ntrainer new misty\nexplore canalave-city-area\n"
+	first := newTestSessionIn(t, dir)
+	first.stopOnError = true
+	if err := first.run(context.Background(), io.Discard, strings.NewReader(script), "script.pdx"); err != nil {
+		t.Errorf("expected no error, got %v", err)
+		return
+	}
+
+	resumed := newTestSessionIn(t, dir)
+	if resumed.Trainer() != "misty" || resumed.state.Area != "canalave-city-area" || len(resumed.pokedex) != 0 {
+		t.Errorf("expected misty to resume in canalave-city-area, got %v in %v", resumed.Trainer(), resumed.state.Area)
+	}
+	if err := resumed.ExecLine(context.Background(), io.Discard, "trainer switch default"); err != nil {
+		t.Errorf("expected no error, got %v", err)
+		return
+	}
+	if resumed.pokedex["buizel"].Name != "buizel" {
+		t.Errorf("expected buizel back, got %v", resumed.pokedex)
+	}
+
+	entries, err := resumed.journal.Read(journal.Filter{})
+	if err != nil || len(entries) != 3 || entries[0].Area != "eterna-city-area" {
+		t.Errorf("expected the old journal followed by the default trainer entries, got %v and %v", entries, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "journal.jsonl")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected the old journal to be moved, got %v", err)
+	}
+}
+
+func TestMissingActiveTrainer(t *testing.T) {
+	dir := t.TempDir()
+	store := trainer.NewStore(filepath.Join(dir, "trainers"))
+	if err := store.SetActive("ghost"); err != nil {
+		t.Fatal(err)
+	}
+	session := newTestSessionIn(t, dir)
+	if session.Trainer() != trainer.DefaultName || store.Active() != trainer.DefaultName {
+		t.Errorf("expected to fall back to the default trainer, got %v with %v active", session.Trainer(), store.Active())
+	}
+}
+
+func TestFailedJournalMigration(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "journal.jsonl"), []byte("{}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// A directory in the way makes the rename fail.
+	store := trainer.NewStore(filepath.Join(dir, "trainers"))
+	if err := os.MkdirAll(filepath.Join(store.JournalPath(trainer.DefaultName), "in-the-way"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	cache := pokecache.NewCache(time.Minute)
+	defer cache.Close()
+	_, err := NewSession(Options{Cache: cache, Config: config.Default(), DataDir: dir})
+	if err == nil || !strings.Contains(err.Error(), "old journal") {
+		t.Errorf("expected the failed move to be reported, got %v", err)
+	}
+	if _, err := store.Load(trainer.DefaultName); !errors.Is(err, trainer.ErrNotFound) {
+		t.Errorf("expected no default trainer until the journal moves, got %v", err)
+	}
+}
+
+func TestLookupCachesOnce(t *testing.T) {
+	session := newTestSession(t)
+	for _, name := range []string{"pikachu", "25", "Pikachu"} {
+		if _, err := session.lookupPokemon(context.Background(), name); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	}
+	if stats := session.cache.Stats(); stats.Entries != 1 {
+		t.Errorf("expected one cache entry for every spelling, got %+v", stats)
+	}
+}
+
+func TestConfigSetOverriddenByEnv(t *testing.T) {
+	session := newTestSession(t)
+	env := map[string]string{"POKEDEX_PAGE_SIZE": "3"}
+	err := session.config.ApplyEnv(func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := &bytes.Buffer{}
+	if err := session.ExecLine(context.Background(), out, "config set page_size 10"); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	expected := "Saved page_size = 10. The POKEDEX_PAGE_SIZE variable overrides it.\n"
+	if out.String() != expected {
+		t.Errorf("expected %q, got %q", expected, out.String())
+	}
+	if session.config.PageSize != 3 || session.config.Source("page_size") != config.SourceEnv {
+		t.Errorf("expected the variable to keep page_size at 3, got %v from %v", session.config.PageSize, session.config.Source("page_size"))
+	}
+}
+
+func TestParentLocation(t *testing.T) {
+	locations := []string{"route-1", "route-10", "canalave-city", ""}
+	cases := []struct {
+		area     string
+		expected string
+	}{
+		{area: "route-1", expected: "route-1"},
+		{area: "route-1-area", expected: "route-1"},
+		{area: "route-10-area", expected: "route-10"},
+		{area: "route-12-area", expected: ""},
+		{area: "canalave-city-area", expected: "canalave-city"},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("Test case %v", i), func(t *testing.T) {
+			if parent := parentLocation(c.area, locations); parent != c.expected {
+				t.Errorf("expected %q, got %q", c.expected, parent)
+			}
+		})
+	}
+}
+
+func TestExitStopsScripts(t *testing.T) {
+	session := newTestSession(t)
+	out := &bytes.Buffer{}
+	err := session.run(context.Background(), out, strings.NewReader("exit\nmap\n"), "script.pdx")
+	if !errors.Is(err, ErrExit) {
+		t.Errorf("expected ErrExit, got %v", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("expected no output after exit, got %q", out.String())
+	}
+}
+
+func TestFailingLinesFailTheScript(t *testing.T) {
+	session := newTestSession(t)
+	out, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	session.stderr = stderr
+	err := session.run(context.Background(), out, strings.NewReader("nope\nhelp exit\n"), "script.pdx")
+	if !errors.Is(err, errScriptFailed) {
+		t.Errorf("expected errScriptFailed, got %v", err)
+	}
+	if stderr.String() != "script.pdx:1: Unknown command \"nope\"\n" {
+		t.Errorf("expected the failing line on stderr, got %q", stderr.String())
+	}
+	if !strings.Contains(out.String(), "exit") || strings.Contains(out.String(), "nope") {
+		t.Errorf("expected only the help output, got %q", out.String())
+	}
+}
+
+func TestCancelStopsScripts(t *testing.T) {
+	session := newTestSession(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	out := &bytes.Buffer{}
+	err := session.run(ctx, out, strings.NewReader("help exit\n"), "script.pdx")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("expected no output after a cancel, got %q", out.String())
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	session := newTestSession(t)
+	session.Registry().Register(command{
+		name:        "hello",
+		description: "Say hello",
+		run: func(_ context.Context, w io.Writer, args []string) error {
+			_, err := fmt.Fprintln(w, "hello", strings.Join(args, " "))
+			return err
+		},
+	})
+	out := &bytes.Buffer{}
+	if err := session.ExecLine(context.Background(), out, "hello ash"); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if out.String() != "hello ash\n" {
+		t.Errorf("expected the registered command to run, got %q", out.String())
+	}
+	commands := session.Registry().Commands()
+	for i := 1; i < len(commands); i++ {
+		if commands[i-1].Name() > commands[i].Name() {
+			t.Errorf("expected commands sorted by name, got %v before %v", commands[i-1].Name(), commands[i].Name())
+		}
+	}
+}
+
+func TestReplayRecordedSession(t *testing.T) {
+	server := fakeapi.NewServer()
+	dir := t.TempDir()
+	script := "map --limit 2\nexplore canalave-city-area\ncatch magikarp\nwhere magikarp\n"
+	run := func(client api.Client) (string, error) {
+		cache := pokecache.NewCache(time.Minute)
+		defer cache.Close()
+		session, err := NewSession(Options{
+			Client:  client,
+			Cache:   cache,
+			Config:  config.Default(),
+			DataDir: t.TempDir(),
+			Format:  render.Text,
+			Random:  func() float64 { return 0 },
+		})
+		if err != nil {
+			return "", err
+		}
+		out := &bytes.Buffer{}
+		session.stopOnError = true
+		err = session.run(context.Background(), out, strings.NewReader(script), "script.pdx")
+		return out.String(), err
+	}
+
+	recorded, err := run(api.NewClient(server.BaseURL(), time.Second).WithTransport(api.Recorder{Dir: dir}))
+	if err != nil {
+		t.Errorf("expected no error while recording, got %v", err)
+	}
+	server.Close()
+	replayed, err := run(api.NewClient(server.BaseURL(), time.Second).WithTransport(api.Replayer{Dir: dir}))
+	if err != nil {
+		t.Errorf("expected no error while replaying, got %v", err)
+	}
+	if replayed != recorded {
+		t.Errorf("expected the replay to match the recording, got %q and %q", replayed, recorded)
+	}
+
+	_, err = run(api.NewClient(server.BaseURL(), time.Second).WithTransport(api.Replayer{Dir: t.TempDir()}))
+	if !errors.Is(err, api.ErrUnrecorded) {
+		t.Errorf("expected unrecorded requests to fail, got %v", err)
+	}
+}
@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/c00rni/pokedex/internal/config"
+)
+
+func (s *Session) commandConfig(_ context.Context, w io.Writer, args []string) error {
+	switch {
+	case len(args) == 0:
+		result := configResult{Settings: []configSetting{}}
+		for _, name := range config.Keys() {
+			value, _ := s.config.Get(name)
+			result.Settings = append(result.Settings, configSetting{Name: name, Value: value, Source: s.config.Source(name)})
+		}
+		return s.render(w, result)
+	case args[0] == "get" && len(args) == 2:
+		value, err := s.config.Get(args[1])
+		if err != nil {
+			return err
+		}
+		return s.render(w, message{Message: value})
+	case args[0] == "set" && len(args) == 3:
+		return s.setConfig(w, args[1], args[2])
+	default:
+		return errors.New("Usage: config [get <name> | set <name> <value>]")
+	}
+}
+
+// setConfig saves a setting to the config file. The session only picks it up
+// when the setting is read after start up and nothing overrides the file.
+func (s *Session) setConfig(w io.Writer, name, value string) error {
+	updated := s.config
+	source := config.SourceFile
+	if s.configPath == "" {
+		source = config.SourceSession
+	}
+	if err := updated.Set(name, value, source); err != nil {
+		return err
+	}
+	if s.configPath == "" && config.NeedsRestart(name) {
+		return fmt.Errorf("The %v setting is read when the Pokedex starts and there is no config file to save it to", name)
+	}
+	saved, _ := updated.Get(name)
+	text := fmt.Sprintf("Set %v = %v for this session.", name, saved)
+	if s.configPath != "" {
+		if err := config.Save(s.configPath, name, value); err != nil {
+			return err
+		}
+		text = fmt.Sprintf("Saved %v = %v.", name, saved)
+	}
+
+	switch {
+	case s.config.Source(name) == config.SourceEnv:
+		return s.render(w, message{Message: fmt.Sprintf("%v The %v variable overrides it.", text, config.EnvName(name))})
+	case s.config.Source(name) == config.SourceFlag:
+		return s.render(w, message{Message: fmt.Sprintf("%v The %v flag overrides it.", text, config.FlagName(name))})
+	case config.NeedsRestart(name):
+		return s.render(w, message{Message: text + " It takes effect the next time the Pokedex starts."})
+	}
+
+	previous := s.config.PageSize
+	s.config = updated
+	// The location list starts over, its pages no longer line up with the
+	// new size.
+	if s.config.PageSize != previous {
+		s.state.Next = s.client.PageURL("location-area", 0, s.config.PageSize)
+		s.state.Previous = ""
+		s.state.Offset = 0
+		s.state.Limit = s.config.PageSize
+	}
+	return s.render(w, message{Message: text})
+}
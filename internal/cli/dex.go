@@ -0,0 +1,240 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/c00rni/pokedex/internal/api"
+	"github.com/c00rni/pokedex/internal/render"
+)
+
+type generation struct {
+	ID         int    `json:"id"`
+	Name       string `json:"name"`
+	MainRegion struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"main_region"`
+	PokemonSpecies []struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"pokemon_species"`
+}
+
+func (g generation) identity() (int, string) {
+	return g.ID, g.Name
+}
+
+// nationalNumber reads the National Dex number from a species link, or from
+// a pokemon link since default forms share their species id. Alternate forms
+// live above 10000 and have no number of their own.
+func nationalNumber(url string) (int, bool) {
+	_, id, ok := api.ParseResourceURL(url)
+	if !ok || id >= 10000 {
+		return 0, false
+	}
+	return id, true
+}
+
+type regionalPokedex struct {
+	ID     int    `json:"id"`
+	Name   string `json:"name"`
+	Region struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"region"`
+	PokemonEntries []struct {
+		EntryNumber    int `json:"entry_number"`
+		PokemonSpecies struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"pokemon_species"`
+	} `json:"pokemon_entries"`
+}
+
+func (r regionalPokedex) identity() (int, string) {
+	return r.ID, r.Name
+}
+
+type dexRow struct {
+	Number int    `json:"number"`
+	Name   string `json:"name"`
+	Status string `json:"status"`
+}
+
+type generationCompletion struct {
+	Generation string `json:"generation"`
+	Region     string `json:"region"`
+	Seen       int    `json:"seen"`
+	Caught     int    `json:"caught"`
+	Total      int    `json:"total"`
+}
+
+type nationalDexResult struct {
+	Entries     []dexRow               `json:"entries,omitempty"`
+	Seen        int                    `json:"seen"`
+	Caught      int                    `json:"caught"`
+	Total       int                    `json:"total"`
+	Generations []generationCompletion `json:"generations"`
+}
+
+func dexStatus(number int, seen, caught map[int]bool) string {
+	switch {
+	case caught[number]:
+		return "caught"
+	case seen[number]:
+		return "seen"
+	default:
+		return "----"
+	}
+}
+
+// dexRows lists entries up to the highest number seen, like the in-game
+// Pokedex, so unknown slots show where the gaps are.
+func dexRows(list response, seen, caught map[int]bool) []dexRow {
+	highest := 0
+	for number := range seen {
+		highest = max(highest, number)
+	}
+	rows := []dexRow{}
+	for _, result := range list.Results {
+		number, ok := nationalNumber(result.URL)
+		if !ok || number > highest {
+			continue
+		}
+		name := result.Name
+		if !seen[number] {
+			name = "???"
+		}
+		rows = append(rows, dexRow{Number: number, Name: name, Status: dexStatus(number, seen, caught)})
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Number < rows[j].Number })
+	return rows
+}
+
+func generationCompletions(generations []generation, seen, caught map[int]bool) []generationCompletion {
+	sort.Slice(generations, func(i, j int) bool { return generations[i].ID < generations[j].ID })
+	completions := []generationCompletion{}
+	for _, g := range generations {
+		c := generationCompletion{
+			Generation: strings.ToUpper(strings.TrimPrefix(g.Name, "generation-")),
+			Region:     g.MainRegion.Name,
+			Total:      len(g.PokemonSpecies),
+		}
+		for _, s := range g.PokemonSpecies {
+			number, ok := nationalNumber(s.URL)
+			if !ok {
+				continue
+			}
+			if seen[number] {
+				c.Seen++
+			}
+			if caught[number] {
+				c.Caught++
+			}
+		}
+		completions = append(completions, c)
+	}
+	return completions
+}
+
+func completion(part, total int) string {
+	if total == 0 {
+		return "0.0%"
+	}
+	return fmt.Sprintf("%.1f%%", float64(part)*100/float64(total))
+}
+
+func (d nationalDexResult) Text() string {
+	lines := []string{}
+	if len(d.Entries) > 0 {
+		lines = append(lines, "National Dex:")
+		for _, row := range d.Entries {
+			lines = append(lines, fmt.Sprintf(" #%04d %-7v %v", row.Number, row.Status, row.Name))
+		}
+	}
+	lines = append(lines, fmt.Sprintf("Completion: seen %v, caught %v / %v (%v)", d.Seen, d.Caught, d.Total, completion(d.Caught, d.Total)))
+	for _, c := range d.Generations {
+		lines = append(lines, fmt.Sprintf(" %v (%v): seen %v, caught %v / %v (%v)", c.Generation, c.Region, c.Seen, c.Caught, c.Total, completion(c.Caught, c.Total)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (d nationalDexResult) Table(style render.Style) ([]string, [][]string) {
+	rows := [][]string{}
+	for _, c := range d.Generations {
+		rows = append(rows, []string{c.Generation, c.Region, fmt.Sprint(c.Seen), fmt.Sprint(c.Caught), fmt.Sprint(c.Total), completion(c.Caught, c.Total)})
+	}
+	return []string{"generation", "region", "seen", "caught", "total", "completion"}, rows
+}
+
+type missingEntry struct {
+	Number int      `json:"number"`
+	Name   string   `json:"name"`
+	Places []string `json:"places,omitempty"`
+}
+
+type regionalDexResult struct {
+	Pokedex string         `json:"pokedex"`
+	Region  string         `json:"region"`
+	Caught  int            `json:"caught"`
+	Total   int            `json:"total"`
+	Missing []missingEntry `json:"missing,omitempty"`
+}
+
+// newRegionalDexResult reports completion of a regional Pokedex and lists the
+// missing entries, with the areas they live in when whereOf is given.
+func newRegionalDexResult(dex regionalPokedex, caught map[int]bool, summaryOnly bool, whereOf func(number int) ([]string, error)) (regionalDexResult, error) {
+	result := regionalDexResult{Pokedex: dex.Name, Region: dex.Region.Name, Total: len(dex.PokemonEntries)}
+	for _, entry := range dex.PokemonEntries {
+		number, ok := nationalNumber(entry.PokemonSpecies.URL)
+		if ok && caught[number] {
+			result.Caught++
+			continue
+		}
+		if summaryOnly {
+			continue
+		}
+		missing := missingEntry{Number: entry.EntryNumber, Name: entry.PokemonSpecies.Name}
+		if whereOf != nil && ok {
+			places, err := whereOf(number)
+			if err != nil {
+				return result, err
+			}
+			missing.Places = places
+		}
+		result.Missing = append(result.Missing, missing)
+	}
+	return result, nil
+}
+
+func (r regionalDexResult) Text() string {
+	lines := []string{fmt.Sprintf("%v Pokedex (%v): caught %v / %v (%v)", r.Pokedex, r.Region, r.Caught, r.Total, completion(r.Caught, r.Total))}
+	if len(r.Missing) > 0 {
+		lines = append(lines, "Missing:")
+	}
+	for _, entry := range r.Missing {
+		line := fmt.Sprintf(" #%03d %v", entry.Number, entry.Name)
+		if entry.Places != nil {
+			places := entry.Places
+			if len(places) > 3 {
+				places = append(places[:3:3], fmt.Sprintf("%v more", len(places)-3))
+			}
+			if len(places) == 0 {
+				places = []string{"not found in the wild"}
+			}
+			line = fmt.Sprintf("%v - %v", line, strings.Join(places, ", "))
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (r regionalDexResult) Table(style render.Style) ([]string, [][]string) {
+	rows := [][]string{}
+	for _, entry := range r.Missing {
+		rows = append(rows, []string{fmt.Sprint(entry.Number), entry.Name, strings.Join(entry.Places, ", ")})
+	}
+	return []string{"number", "missing", "places"}, rows
+}
@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// TestGolden runs testdata/<command>.pdx against the fake API for every
+// registered command and compares the output with testdata/<command>.golden.
+// Run go test ./internal/cli -update after changing an output on purpose.
+func TestGolden(t *testing.T) {
+	for _, c := range newTestSession(t).Registry().Commands() {
+		t.Run(c.Name(), func(t *testing.T) {
+			script := filepath.Join("testdata", c.Name()+".pdx")
+			file, err := os.Open(script)
+			if err != nil {
+				t.Errorf("expected a golden script for the %v command, got %v", c.Name(), err)
+				return
+			}
+			defer file.Close()
+
+			// The errors of failing lines go with the output, where they happened.
+			out := &bytes.Buffer{}
+			session := newTestSession(t)
+			session.stderr = out
+			err = session.run(context.Background(), out, file, script)
+			if err != nil && !errors.Is(err, ErrExit) && !errors.Is(err, errScriptFailed) {
+				t.Errorf("expected the script to run, got %v", err)
+				return
+			}
+
+			golden := filepath.Join("testdata", c.Name()+".golden")
+			if *update {
+				if err := os.WriteFile(golden, out.Bytes(), 0o644); err != nil {
+					t.Errorf("expected to update %v, got %v", golden, err)
+				}
+				return
+			}
+			expected, err := os.ReadFile(golden)
+			if err != nil {
+				t.Errorf("expected a golden file, got %v", err)
+				return
+			}
+			if !bytes.Equal(out.Bytes(), expected) {
+				t.Errorf("expected the output of %v to match %v, got:\n%v", script, golden, out.String())
+			}
+		})
+	}
+}
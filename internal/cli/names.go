@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/c00rni/pokedex/internal/api"
+	"github.com/c00rni/pokedex/internal/fuzzy"
+	"github.com/c00rni/pokedex/internal/search"
+)
+
+var searchKinds = map[string]string{
+	"pokemon": "pokemon",
+	"area":    "location-area",
+	"move":    "move",
+	"item":    "item",
+}
+
+type unknownPokemonError struct {
+	name        string
+	suggestions []string
+}
+
+func (e unknownPokemonError) Error() string {
+	if len(e.suggestions) == 0 {
+		return fmt.Sprintf("Unknown pokemon %q.", e.name)
+	}
+	return fmt.Sprintf("Unknown pokemon %q. Did you mean: %v?", e.name, strings.Join(e.suggestions, ", "))
+}
+
+func (e unknownPokemonError) Unwrap() error {
+	return api.ErrNotFound
+}
+
+func loadIndex(path string) search.Index {
+	index, err := search.Load(path)
+	if err != nil {
+		return search.NewIndex(path)
+	}
+	return index
+}
+
+func (s *Session) ensureIndexed(ctx context.Context, kind string) error {
+	// Explored areas and looked up pokemon are in the index too, only a full
+	// list makes the kind complete.
+	if s.index.Complete(kind) {
+		return nil
+	}
+	response, err := s.page(ctx, s.client.PageURL(kind, 0, 100000))
+	if err != nil {
+		return err
+	}
+	learnAliases(s.aliases, response)
+	for _, result := range response.Results {
+		s.index.Add(kind, result.Name)
+	}
+	s.index.MarkComplete(kind)
+	// The index still works from memory when the disk cache isn't writable.
+	s.index.Save()
+	return nil
+}
+
+func (s *Session) unknownPokemon(ctx context.Context, name string) error {
+	if err := s.ensureIndexed(ctx, "pokemon"); err != nil {
+		return unknownPokemonError{name: name}
+	}
+	return unknownPokemonError{name: name, suggestions: fuzzy.Closest(name, s.index.Names("pokemon"), 3)}
+}
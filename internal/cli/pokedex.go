@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type species struct {
+	ID         int    `json:"id"`
+	Name       string `json:"name"`
+	Generation struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"generation"`
+	PokedexNumbers []struct {
+		EntryNumber int `json:"entry_number"`
+		Pokedex     struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"pokedex"`
+	} `json:"pokedex_numbers"`
+}
+
+func (s species) identity() (int, string) {
+	return s.ID, s.Name
+}
+
+type caughtPokemon struct {
+	pokemon
+	CaughtAt time.Time
+}
+
+func (p pokemon) baseStatTotal() int {
+	total := 0
+	for _, stats := range p.Stats {
+		total += stats.BaseStat
+	}
+	return total
+}
+
+func (p pokemon) hasType(name string) bool {
+	for _, types := range p.Types {
+		if types.Type.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+var romanNumerals = map[string]int{
+	"i": 1, "ii": 2, "iii": 3, "iv": 4, "v": 5, "vi": 6, "vii": 7, "viii": 8, "ix": 9, "x": 10,
+}
+
+// generationNumber turns PokeAPI generation names such as generation-iii
+// into their number.
+func generationNumber(name string) int {
+	return romanNumerals[strings.TrimPrefix(name, "generation-")]
+}
+
+type pokedexQuery struct {
+	sortBy     string
+	types      []string
+	generation int
+	minBST     int
+}
+
+func parsePokedexQuery(opts []string) (pokedexQuery, error) {
+	query := pokedexQuery{sortBy: "name"}
+	for i := 0; i < len(opts); i++ {
+		if i+1 >= len(opts) {
+			return query, fmt.Errorf("The %v option needs a value", opts[i])
+		}
+		value := strings.ToLower(opts[i+1])
+		switch opts[i] {
+		case "--sort":
+			switch value {
+			case "name", "id", "caught", "bst":
+				query.sortBy = value
+			default:
+				return query, fmt.Errorf("Unknown sort %q, expected name, id, caught or bst", value)
+			}
+		case "--type":
+			query.types = append(query.types, value)
+		case "--gen":
+			generation, err := strconv.Atoi(value)
+			if err != nil || generation < 1 {
+				return query, fmt.Errorf("The --gen option needs a generation number, got %q", value)
+			}
+			query.generation = generation
+		case "--min-bst":
+			bst, err := strconv.Atoi(value)
+			if err != nil {
+				return query, fmt.Errorf("The --min-bst option needs a number, got %q", value)
+			}
+			query.minBST = bst
+		default:
+			return query, fmt.Errorf("Unknown pokedex option %q", opts[i])
+		}
+		i++
+	}
+	return query, nil
+}
+
+// filter keeps the caught pokemon matching every criterion. The generation
+// lives on the species, so it is only resolved when --gen is used.
+func (q pokedexQuery) filter(caught []caughtPokemon, generationOf func(pokemon) (int, error)) ([]caughtPokemon, error) {
+	kept := []caughtPokemon{}
+	for _, c := range caught {
+		if c.baseStatTotal() < q.minBST {
+			continue
+		}
+		matchesTypes := true
+		for _, name := range q.types {
+			matchesTypes = matchesTypes && c.hasType(name)
+		}
+		if !matchesTypes {
+			continue
+		}
+		if q.generation > 0 {
+			generation, err := generationOf(c.pokemon)
+			if err != nil {
+				return nil, err
+			}
+			if generation != q.generation {
+				continue
+			}
+		}
+		kept = append(kept, c)
+	}
+	return kept, nil
+}
+
+func (q pokedexQuery) sort(caught []caughtPokemon) {
+	less := map[string]func(a, b caughtPokemon) bool{
+		"name":   func(a, b caughtPokemon) bool { return a.Name < b.Name },
+		"id":     func(a, b caughtPokemon) bool { return a.ID < b.ID },
+		"caught": func(a, b caughtPokemon) bool { return a.CaughtAt.Before(b.CaughtAt) },
+		"bst":    func(a, b caughtPokemon) bool { return a.baseStatTotal() > b.baseStatTotal() },
+	}[q.sortBy]
+	// Sorting by name first keeps ties in a stable order between runs.
+	sort.SliceStable(caught, func(i, j int) bool { return caught[i].Name < caught[j].Name })
+	sort.SliceStable(caught, func(i, j int) bool { return less(caught[i], caught[j]) })
+}
@@ -0,0 +1,441 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/c00rni/pokedex/internal/journal"
+	"github.com/c00rni/pokedex/internal/render"
+)
+
+type message struct {
+	Message string `json:"message"`
+}
+
+func (m message) Text() string {
+	return m.Message
+}
+
+type listing struct {
+	Title string   `json:"title"`
+	Items []string `json:"items"`
+}
+
+func (l listing) Text() string {
+	lines := []string{l.Title}
+	for _, item := range l.Items {
+		lines = append(lines, " - "+item)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (l listing) Table(style render.Style) ([]string, [][]string) {
+	rows := [][]string{}
+	for _, item := range l.Items {
+		rows = append(rows, []string{item})
+	}
+	return []string{"name"}, rows
+}
+
+type helpEntry struct {
+	Name        string `json:"name"`
+	Usage       string `json:"usage"`
+	Description string `json:"description"`
+}
+
+type helpResult struct {
+	Commands []helpEntry `json:"commands"`
+}
+
+func (h helpResult) Text() string {
+	lines := []string{"Welcome to the Pokedex!", "Usage:"}
+	for _, command := range h.Commands {
+		line := fmt.Sprintf("%v: %v", command.Name, command.Description)
+		if command.Usage != command.Name {
+			line += fmt.Sprintf(" (%v)", command.Usage)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (h helpResult) Table(style render.Style) ([]string, [][]string) {
+	rows := [][]string{}
+	for _, command := range h.Commands {
+		rows = append(rows, []string{command.Usage, command.Description})
+	}
+	return []string{"usage", "description"}, rows
+}
+
+type locationPage struct {
+	Page      int      `json:"page"`
+	Pages     int      `json:"pages"`
+	Count     int      `json:"count"`
+	Locations []string `json:"locations"`
+}
+
+func (p locationPage) Text() string {
+	lines := []string{fmt.Sprintf("Page %v of %v", p.Page, p.Pages)}
+	lines = append(lines, p.Locations...)
+	return strings.Join(lines, "\n")
+}
+
+func (p locationPage) Table(style render.Style) ([]string, [][]string) {
+	rows := [][]string{}
+	for _, name := range p.Locations {
+		rows = append(rows, []string{name})
+	}
+	return []string{"location area"}, rows
+}
+
+type exploreResult struct {
+	Region   string   `json:"region,omitempty"`
+	Location string   `json:"location"`
+	Area     string   `json:"area"`
+	Pokemon  []string `json:"pokemon"`
+}
+
+func (e exploreResult) Text() string {
+	lines := []string{fmt.Sprintf("Exploring %v...", breadcrumb(e.Region, e.Location, e.Area)), "Found Pokemon:"}
+	for _, name := range e.Pokemon {
+		lines = append(lines, " - "+name)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (e exploreResult) Table(style render.Style) ([]string, [][]string) {
+	rows := [][]string{}
+	for _, name := range e.Pokemon {
+		rows = append(rows, []string{name})
+	}
+	return []string{"pokemon"}, rows
+}
+
+type catchResult struct {
+	Pokemon string `json:"pokemon"`
+	Outcome string `json:"outcome"`
+}
+
+func (c catchResult) Text() string {
+	outcome := fmt.Sprintf("%v escaped!", c.Pokemon)
+	if c.Outcome == journal.OutcomeCaught {
+		outcome = fmt.Sprintf("%v was caught!", c.Pokemon)
+	}
+	return fmt.Sprintf("Throwing a Pokeball at %v...\n%v", c.Pokemon, outcome)
+}
+
+type statValue struct {
+	Name  string `json:"name"`
+	Value int    `json:"value"`
+}
+
+// Blissey's HP is the highest base stat in the games.
+const maxBaseStat = 255
+
+type inspectResult struct {
+	Name   string      `json:"name"`
+	ID     int         `json:"id"`
+	Height int         `json:"height"`
+	Weight int         `json:"weight"`
+	Stats  []statValue `json:"stats"`
+	Types  []string    `json:"types"`
+}
+
+func newInspectResult(p pokemon) inspectResult {
+	result := inspectResult{Name: p.Name, ID: p.ID, Height: p.Height, Weight: p.Weight, Stats: []statValue{}, Types: []string{}}
+	for _, stats := range p.Stats {
+		result.Stats = append(result.Stats, statValue{Name: stats.Stat.Name, Value: stats.BaseStat})
+	}
+	for _, types := range p.Types {
+		result.Types = append(result.Types, types.Type.Name)
+	}
+	return result
+}
+
+func (i inspectResult) Text() string {
+	lines := []string{fmt.Sprintf("Name: %v\nHeight: %v\nWeight: %v\nStats:", i.Name, i.Height, i.Weight)}
+	for _, stats := range i.Stats {
+		lines = append(lines, fmt.Sprintf(" - %v: %v", stats.Name, stats.Value))
+	}
+	lines = append(lines, "Types:")
+	for _, name := range i.Types {
+		lines = append(lines, fmt.Sprintf(" - %v", name))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (i inspectResult) Table(style render.Style) ([]string, [][]string) {
+	rows := [][]string{}
+	for _, stats := range i.Stats {
+		rows = append(rows, []string{stats.Name, strconv.Itoa(stats.Value), style.Bar(stats.Value, maxBaseStat, 20)})
+	}
+	rows = append(rows, []string{"types", "", style.Types(i.Types)})
+	return []string{"stat", "value", ""}, rows
+}
+
+type pokedexRow struct {
+	Name     string    `json:"name"`
+	ID       int       `json:"id"`
+	Types    []string  `json:"types"`
+	BST      int       `json:"bst"`
+	CaughtAt time.Time `json:"caught_at"`
+}
+
+type pokedexResult struct {
+	Pokemon []pokedexRow `json:"pokemon"`
+	Shown   int          `json:"shown"`
+	Caught  int          `json:"caught"`
+	Seen    int          `json:"seen"`
+}
+
+func (p pokedexResult) Text() string {
+	lines := []string{"Your Pokedex:"}
+	for _, row := range p.Pokemon {
+		lines = append(lines, " - "+row.Name)
+	}
+	summary := fmt.Sprintf("%v caught / %v seen", p.Caught, p.Seen)
+	if p.Shown != p.Caught {
+		summary = fmt.Sprintf("%v shown, %v", p.Shown, summary)
+	}
+	return strings.Join(append(lines, summary), "\n")
+}
+
+func (p pokedexResult) Table(style render.Style) ([]string, [][]string) {
+	rows := [][]string{}
+	for _, row := range p.Pokemon {
+		rows = append(rows, []string{strconv.Itoa(row.ID), row.Name, style.Types(row.Types), strconv.Itoa(row.BST)})
+	}
+	return []string{"id", "name", "types", "bst"}, rows
+}
+
+type searchHit struct {
+	Name  string `json:"name"`
+	Kind  string `json:"kind"`
+	Score int    `json:"score"`
+}
+
+type searchResult struct {
+	Query   string      `json:"query"`
+	Results []searchHit `json:"results"`
+}
+
+func (s searchResult) Text() string {
+	if len(s.Results) == 0 {
+		return fmt.Sprintf("No match for %q.", s.Query)
+	}
+	lines := []string{"Results:"}
+	for _, hit := range s.Results {
+		lines = append(lines, fmt.Sprintf(" - %v (%v)", hit.Name, hit.Kind))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (s searchResult) Table(style render.Style) ([]string, [][]string) {
+	rows := [][]string{}
+	for _, hit := range s.Results {
+		rows = append(rows, []string{hit.Name, hit.Kind, strconv.Itoa(hit.Score)})
+	}
+	return []string{"name", "kind", "score"}, rows
+}
+
+type encounterSummary struct {
+	Version  string `json:"version"`
+	Method   string `json:"method"`
+	MinLevel int    `json:"min_level"`
+	MaxLevel int    `json:"max_level"`
+	Chance   int    `json:"chance"`
+}
+
+type whereArea struct {
+	Area       string             `json:"area"`
+	Encounters []encounterSummary `json:"encounters"`
+}
+
+type whereResult struct {
+	Pokemon string      `json:"pokemon"`
+	Areas   []whereArea `json:"areas"`
+}
+
+// newWhereResult merges the encounter details of each area per version and
+// method, keeping the level range and summing the chances.
+func newWhereResult(name, version string, found []encounter) whereResult {
+	result := whereResult{Pokemon: name, Areas: []whereArea{}}
+	for _, place := range found {
+		summaries := []encounterSummary{}
+		for _, versionDetails := range place.VersionDetails {
+			if version != "" && versionDetails.Version.Name != version {
+				continue
+			}
+			byMethod := map[string]int{}
+			for _, detail := range versionDetails.EncounterDetails {
+				i, ok := byMethod[detail.Method.Name]
+				if !ok {
+					byMethod[detail.Method.Name] = len(summaries)
+					summaries = append(summaries, encounterSummary{
+						Version:  versionDetails.Version.Name,
+						Method:   detail.Method.Name,
+						MinLevel: detail.MinLevel,
+						MaxLevel: detail.MaxLevel,
+						Chance:   detail.Chance,
+					})
+					continue
+				}
+				summaries[i].MinLevel = min(summaries[i].MinLevel, detail.MinLevel)
+				summaries[i].MaxLevel = max(summaries[i].MaxLevel, detail.MaxLevel)
+				summaries[i].Chance += detail.Chance
+			}
+		}
+		if len(summaries) > 0 {
+			result.Areas = append(result.Areas, whereArea{Area: place.LocationArea.Name, Encounters: summaries})
+		}
+	}
+	return result
+}
+
+func (w whereResult) Text() string {
+	if len(w.Areas) == 0 {
+		return fmt.Sprintf("%v can't be found in the wild.", w.Pokemon)
+	}
+	lines := []string{fmt.Sprintf("%v can be found in:", w.Pokemon)}
+	for _, place := range w.Areas {
+		lines = append(lines, " - "+place.Area)
+		for _, s := range place.Encounters {
+			lines = append(lines, fmt.Sprintf("     %v: %v, lv %v-%v, %v%%", s.Version, s.Method, s.MinLevel, s.MaxLevel, s.Chance))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (w whereResult) Table(style render.Style) ([]string, [][]string) {
+	rows := [][]string{}
+	for _, place := range w.Areas {
+		for _, s := range place.Encounters {
+			rows = append(rows, []string{place.Area, s.Version, s.Method, fmt.Sprintf("%v-%v", s.MinLevel, s.MaxLevel), fmt.Sprintf("%v%%", s.Chance)})
+		}
+	}
+	return []string{"area", "version", "method", "levels", "chance"}, rows
+}
+
+type journalResult struct {
+	Entries []journal.Entry `json:"entries"`
+}
+
+func (j journalResult) Text() string {
+	if len(j.Entries) == 0 {
+		return "The journal is empty."
+	}
+	lines := []string{}
+	for _, entry := range j.Entries {
+		line := fmt.Sprintf("%v %v", entry.Time.Format(time.DateTime), entry.Kind)
+		if entry.Pokemon != "" {
+			line += " " + entry.Pokemon
+		}
+		if entry.Outcome != "" {
+			line += ": " + entry.Outcome
+		}
+		if entry.Area != "" {
+			line += " in " + entry.Area
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (j journalResult) Table(style render.Style) ([]string, [][]string) {
+	rows := [][]string{}
+	for _, entry := range j.Entries {
+		rows = append(rows, []string{entry.Time.Format(time.DateTime), entry.Kind, entry.Pokemon, entry.Outcome, entry.Area})
+	}
+	return []string{"time", "kind", "pokemon", "outcome", "area"}, rows
+}
+
+type statsResult struct {
+	PerSpecies []journal.CatchRate `json:"per_species"`
+	PerBall    []journal.CatchRate `json:"per_ball"`
+}
+
+func (s statsResult) Text() string {
+	if len(s.PerSpecies) == 0 {
+		return "No catch attempts yet."
+	}
+	lines := []string{"Catch rate per species:"}
+	for _, rate := range s.PerSpecies {
+		lines = append(lines, fmt.Sprintf(" - %v: %v/%v (%.0f%%)", rate.Key, rate.Caught, rate.Attempts, rate.Rate()*100))
+	}
+	lines = append(lines, "Catch rate per ball:")
+	for _, rate := range s.PerBall {
+		lines = append(lines, fmt.Sprintf(" - %v: %v/%v (%.0f%%)", rate.Key, rate.Caught, rate.Attempts, rate.Rate()*100))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (s statsResult) Table(style render.Style) ([]string, [][]string) {
+	rows := [][]string{}
+	for _, rate := range s.PerSpecies {
+		rows = append(rows, []string{"species", rate.Key, strconv.Itoa(rate.Caught), strconv.Itoa(rate.Attempts)})
+	}
+	for _, rate := range s.PerBall {
+		rows = append(rows, []string{"ball", rate.Key, strconv.Itoa(rate.Caught), strconv.Itoa(rate.Attempts)})
+	}
+	return []string{"group", "key", "caught", "attempts"}, rows
+}
+
+type cacheStats struct {
+	Entries   int    `json:"entries"`
+	Bytes     int    `json:"bytes"`
+	Hits      uint64 `json:"hits"`
+	Misses    uint64 `json:"misses"`
+	Evictions uint64 `json:"evictions"`
+}
+
+func (c cacheStats) Text() string {
+	return fmt.Sprintf("Entries: %v\nBytes: %v\nHits: %v\nMisses: %v\nEvictions: %v", c.Entries, c.Bytes, c.Hits, c.Misses, c.Evictions)
+}
+
+type trainerResult struct {
+	Name     string `json:"name"`
+	Location string `json:"location"`
+	Caught   int    `json:"caught"`
+	Seen     int    `json:"seen"`
+}
+
+func (t trainerResult) Text() string {
+	location := t.Location
+	if location == "" {
+		location = "nowhere yet"
+	}
+	lines := []string{
+		fmt.Sprintf("Trainer: %v", t.Name),
+		fmt.Sprintf("Location: %v", location),
+		fmt.Sprintf("Caught: %v, seen: %v", t.Caught, t.Seen),
+	}
+	return strings.Join(lines, "\n")
+}
+
+type configSetting struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+type configResult struct {
+	Settings []configSetting `json:"settings"`
+}
+
+func (c configResult) Text() string {
+	lines := []string{"Settings:"}
+	for _, setting := range c.Settings {
+		lines = append(lines, fmt.Sprintf(" - %v = %v (%v)", setting.Name, setting.Value, setting.Source))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (c configResult) Table(style render.Style) ([]string, [][]string) {
+	rows := [][]string{}
+	for _, setting := range c.Settings {
+		rows = append(rows, []string{setting.Name, setting.Value, setting.Source})
+	}
+	return []string{"name", "value", "source"}, rows
+}
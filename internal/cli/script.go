@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/c00rni/pokedex/internal/render"
+)
+
+const maxSourceDepth = 10
+
+// errScriptFailed ends a script that kept going past failing lines.
+var errScriptFailed = errors.New("Some commands of the script failed")
+
+func (s *Session) expand(line string) string {
+	return os.Expand(line, func(name string) string {
+		if value, ok := s.vars[name]; ok {
+			return value
+		}
+		return os.Getenv(name)
+	})
+}
+
+// ExecLine executes one command line for the REPL and for script files.
+// Lines starting with # are comments, NAME=value assigns a variable that
+// later lines expand with $NAME, and set -e makes scripts stop at the first
+// failing command.
+func (s *Session) ExecLine(ctx context.Context, w io.Writer, line string) error {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return nil
+	}
+	inputs := strings.Fields(s.expand(line))
+	// A line of unset variables expands to nothing.
+	if len(inputs) == 0 {
+		return nil
+	}
+	if name, value, ok := strings.Cut(inputs[0], "="); ok && len(inputs) == 1 && name != "" {
+		s.vars[name] = value
+		return nil
+	}
+	if inputs[0] == "set" && len(inputs) == 2 && (inputs[1] == "-e" || inputs[1] == "+e") {
+		s.stopOnError = inputs[1] == "-e"
+		return nil
+	}
+	return s.Exec(ctx, w, inputs)
+}
+
+// Exec runs one command. An --output option anywhere on the line renders
+// that command in another format, and on its own it changes the format for
+// every following command.
+func (s *Session) Exec(ctx context.Context, w io.Writer, inputs []string) error {
+	args := []string{}
+	format, hasFormat := s.Format, false
+	for i := 0; i < len(inputs); i++ {
+		value, ok := strings.CutPrefix(inputs[i], "--output=")
+		if !ok && (inputs[i] == "--output" || inputs[i] == "-o") && i+1 < len(inputs) {
+			value, ok = inputs[i+1], true
+			i++
+		}
+		if !ok {
+			args = append(args, inputs[i])
+			continue
+		}
+		parsed, err := render.ParseFormat(value)
+		if err != nil {
+			return err
+		}
+		format, hasFormat = parsed, true
+	}
+
+	if len(args) == 0 {
+		if hasFormat {
+			s.Format = format
+		}
+		return nil
+	}
+	cmd, ok := s.registry.Lookup(args[0])
+	if !ok {
+		return UnknownCommandError{Name: args[0]}
+	}
+	previous := s.Format
+	s.Format = format
+	defer func() {
+		s.Format = previous
+	}()
+	return cmd.Run(ctx, w, args[1:])
+}
+
+func (s *Session) run(ctx context.Context, w io.Writer, reader io.Reader, name string) error {
+	if s.depth >= maxSourceDepth {
+		return errors.New("Scripts are sourcing each other too deeply")
+	}
+	s.depth++
+	// set -e only lasts for the script that enabled it.
+	stopOnError := s.stopOnError
+	defer func() {
+		s.depth--
+		s.stopOnError = stopOnError
+	}()
+
+	failed := false
+	scanner := bufio.NewScanner(reader)
+	for number := 1; scanner.Scan(); number++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		err := s.ExecLine(ctx, w, scanner.Text())
+		if err == nil {
+			continue
+		}
+		if errors.Is(err, ErrExit) {
+			return err
+		}
+		err = fmt.Errorf("%v:%v: %w", name, number, err)
+		if s.stopOnError {
+			return err
+		}
+		// Errors stay out of w, which may hold JSON or YAML output.
+		fmt.Fprintln(s.stderr, err)
+		failed = true
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if failed {
+		return fmt.Errorf("%v: %w", name, errScriptFailed)
+	}
+	return nil
+}
+
+func (s *Session) source(ctx context.Context, w io.Writer, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return s.run(ctx, w, file, path)
+}
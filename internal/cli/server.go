@@ -0,0 +1,203 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/c00rni/pokedex/internal/api"
+	"github.com/c00rni/pokedex/internal/journal"
+	"github.com/c00rni/pokedex/internal/render"
+)
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+type catchRequest struct {
+	Pokemon string `json:"pokemon"`
+}
+
+// Handler exposes the session as a JSON API:
+//
+//	GET  /areas?offset=0&limit=20  a page of location areas
+//	GET  /areas/{name}             explore an area
+//	POST /catch                    {"pokemon": "pikachu"}
+//	GET  /pokedex?sort=bst&type=x  the caught pokemon, with the pokedex filters
+//	GET  /pokedex/{name}           stats of a caught pokemon
+//
+// Requests share the session state, so they are served one at a time.
+func (s *Session) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /areas", s.serveAreas)
+	mux.HandleFunc("GET /areas/{name}", s.serveArea)
+	mux.HandleFunc("POST /catch", s.serveCatch)
+	mux.HandleFunc("GET /pokedex", s.servePokedex)
+	mux.HandleFunc("GET /pokedex/{name}", s.servePokemon)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		mux.ServeHTTP(w, r)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	out := render.Renderer{Writer: w, Format: render.JSON}
+	out.Render(v)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	var rangeErr pageRangeError
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, errAlreadyCaught):
+		status = http.StatusConflict
+	case errors.Is(err, api.ErrNotFound), errors.As(err, &rangeErr):
+		status = http.StatusNotFound
+	case errors.Is(err, api.ErrRateLimited):
+		status = http.StatusServiceUnavailable
+	case errors.Is(err, api.ErrUpstream):
+		status = http.StatusBadGateway
+	}
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
+
+func badRequest(w http.ResponseWriter, err error) {
+	writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+}
+
+func (s *Session) serveAreas(w http.ResponseWriter, r *http.Request) {
+	offset, limit := 0, s.config.PageSize
+	params := []struct {
+		name  string
+		value *int
+		min   int
+	}{{"offset", &offset, 0}, {"limit", &limit, 1}}
+	for _, param := range params {
+		raw := r.URL.Query().Get(param.name)
+		if raw == "" {
+			continue
+		}
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < param.min {
+			badRequest(w, fmt.Errorf("The %v parameter needs a number from %v, got %q", param.name, param.min, raw))
+			return
+		}
+		*param.value = parsed
+	}
+	page, _, err := s.locationPage(r.Context(), s.client.PageURL("location-area", offset, limit))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+func (s *Session) serveArea(w http.ResponseWriter, r *http.Request) {
+	result, err := s.explore(r.Context(), r.PathValue("name"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Session) serveCatch(w http.ResponseWriter, r *http.Request) {
+	request := catchRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		badRequest(w, fmt.Errorf("The body needs to be a JSON object such as {\"pokemon\": \"pikachu\"}: %w", err))
+		return
+	}
+	if request.Pokemon == "" {
+		badRequest(w, errors.New("The pokemon field is required"))
+		return
+	}
+	result, err := s.catch(r.Context(), request.Pokemon)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	status := http.StatusOK
+	if result.Outcome == journal.OutcomeCaught {
+		w.Header().Set("Location", "/pokedex/"+result.Pokemon)
+		status = http.StatusCreated
+	}
+	writeJSON(w, status, result)
+}
+
+func (s *Session) servePokedex(w http.ResponseWriter, r *http.Request) {
+	args := []string{}
+	for _, name := range []string{"sort", "type", "gen", "min-bst"} {
+		for _, value := range r.URL.Query()[name] {
+			args = append(args, "--"+name, value)
+		}
+	}
+	query, err := parsePokedexQuery(args)
+	if err != nil {
+		badRequest(w, err)
+		return
+	}
+	result, err := s.listPokedex(r.Context(), query)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Session) servePokemon(w http.ResponseWriter, r *http.Request) {
+	caught, ok, err := s.caught(r.Context(), r.PathValue("name"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if !ok {
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: fmt.Sprintf("%v is not in the pokedex", r.PathValue("name"))})
+		return
+	}
+	writeJSON(w, http.StatusOK, newInspectResult(caught.pokemon))
+}
+
+func (s *Session) commandServe(ctx context.Context, w io.Writer, args []string) error {
+	addr := ":8080"
+	for i := 0; i < len(args); i++ {
+		if args[i] != "--addr" {
+			return fmt.Errorf("Unknown serve option %q", args[i])
+		}
+		if i+1 >= len(args) {
+			return errors.New("The --addr option needs a host:port such as :8080")
+		}
+		addr = args[i+1]
+		i++
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	if err := s.render(w, message{Message: fmt.Sprintf("Serving the Pokedex on http://%v", listener.Addr())}); err != nil {
+		listener.Close()
+		return err
+	}
+
+	server := &http.Server{Handler: s.Handler()}
+	done := make(chan error, 1)
+	go func() {
+		done <- server.Serve(listener)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return server.Shutdown(shutdown)
+	}
+}
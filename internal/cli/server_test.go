@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandler(t *testing.T) {
+	session := newTestSession(t)
+	server := httptest.NewServer(session.Handler())
+	defer server.Close()
+
+	// The cases share the session, so their order matters.
+	cases := []struct {
+		method   string
+		path     string
+		body     string
+		status   int
+		contains string
+	}{
+		{method: "GET", path: "/areas", status: http.StatusOK, contains: `"sinnoh-pokemon-league-area"`},
+		{method: "GET", path: "/areas?offset=2&limit=2", status: http.StatusOK, contains: `"page": 2`},
+		{method: "GET", path: "/areas?offset=40", status: http.StatusNotFound, contains: "out of range"},
+		{method: "GET", path: "/areas?limit=0", status: http.StatusBadRequest, contains: "limit parameter"},
+		{method: "GET", path: "/areas/pastoria-city-area", status: http.StatusOK, contains: `"buizel"`},
+		{method: "GET", path: "/areas/cerulean-cave", status: http.StatusNotFound, contains: `"error"`},
+		{method: "POST", path: "/areas", status: http.StatusMethodNotAllowed},
+		{method: "GET", path: "/pokedex", status: http.StatusOK, contains: `"caught": 0`},
+		{method: "POST", path: "/catch", body: `{"pokemon": "buizel"}`, status: http.StatusCreated, contains: `"outcome": "caught"`},
+		{method: "POST", path: "/catch", body: `{"pokemon": "buizel"}`, status: http.StatusConflict, contains: "already captured"},
+		{method: "POST", path: "/catch", body: `{"pokemon": "pikachoo"}`, status: http.StatusNotFound, contains: "Did you mean: pikachu?"},
+		{method: "POST", path: "/catch", body: `pikachu`, status: http.StatusBadRequest, contains: "JSON object"},
+		{method: "POST", path: "/catch", body: `{}`, status: http.StatusBadRequest, contains: "pokemon field"},
+		{method: "GET", path: "/catch", status: http.StatusMethodNotAllowed},
+		{method: "GET", path: "/pokedex?type=water&sort=bst", status: http.StatusOK, contains: `"name": "buizel"`},
+		{method: "GET", path: "/pokedex?sort=weight", status: http.StatusBadRequest, contains: "Unknown sort"},
+		{method: "GET", path: "/pokedex/buizel", status: http.StatusOK, contains: `"types": [`},
+		{method: "GET", path: "/pokedex/418", status: http.StatusOK, contains: `"name": "buizel"`},
+		{method: "POST", path: "/catch", body: `{"pokemon": "418"}`, status: http.StatusConflict, contains: "already captured"},
+		{method: "GET", path: "/pokedex/pikachu", status: http.StatusNotFound, contains: "not in the pokedex"},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("Test case %v", i), func(t *testing.T) {
+			req, err := http.NewRequest(c.method, server.URL+c.path, strings.NewReader(c.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			res, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Errorf("expected no error, got %v", err)
+				return
+			}
+			defer res.Body.Close()
+			body, _ := io.ReadAll(res.Body)
+			if res.StatusCode != c.status {
+				t.Errorf("expected status %v for %v %v, got %v: %s", c.status, c.method, c.path, res.StatusCode, body)
+			}
+			if !strings.Contains(string(body), c.contains) {
+				t.Errorf("expected %q in the response, got %s", c.contains, body)
+			}
+		})
+	}
+}
+
+func TestServeStopsWithContext(t *testing.T) {
+	session := newTestSession(t)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	out := &bytes.Buffer{}
+	go func() {
+		done <- session.Exec(ctx, out, []string{"serve", "--addr", addr})
+	}()
+
+	var res *http.Response
+	for i := 0; i < 50; i++ {
+		if res, err = http.Get("http://" + addr + "/pokedex"); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Errorf("expected the server to answer, got %v", err)
+	} else {
+		res.Body.Close()
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("expected a clean shutdown, got %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "Serving the Pokedex on http://"+addr) {
+		t.Errorf("expected the address to be printed, got %q", out.String())
+	}
+}
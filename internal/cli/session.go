@@ -0,0 +1,264 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/c00rni/pokedex/internal/api"
+	"github.com/c00rni/pokedex/internal/config"
+	"github.com/c00rni/pokedex/internal/journal"
+	"github.com/c00rni/pokedex/internal/pokecache"
+	"github.com/c00rni/pokedex/internal/render"
+	"github.com/c00rni/pokedex/internal/search"
+	"github.com/c00rni/pokedex/internal/trainer"
+)
+
+type Options struct {
+	Client api.Client
+	Cache  pokecache.Cache
+	// Config holds the page size and catch threshold, config set saves its
+	// changes to ConfigPath unless it is empty.
+	Config     config.Config
+	ConfigPath string
+	// DataDir holds the trainers and their journals.
+	DataDir string
+	// An empty path keeps the search index out of the disk.
+	IndexPath string
+	Format    render.Format
+	Style     render.Style
+	// Random draws the catch roll, rand.NormFloat64 when nil.
+	Random func() float64
+	// Now dates catches and journal entries, time.Now when nil.
+	Now func() time.Time
+	// Stderr gets the errors of the script lines that don't stop the script,
+	// os.Stderr when nil.
+	Stderr io.Writer
+}
+
+// state is where the trainer stands in the world and in the location list.
+type state struct {
+	Next     string
+	Previous string
+	Offset   int
+	Limit    int
+	Count    int
+	Region   string
+	Location string
+	Area     string
+}
+
+// Session holds everything the commands share: the API client and caches,
+// the trainer state and collection, and the script runner settings.
+type Session struct {
+	Format render.Format
+	Style  render.Style
+
+	client  api.Client
+	cache   pokecache.Cache
+	aliases pokecache.Aliases
+	index   search.Index
+	journal journal.Journal
+	random  func() float64
+	now     func() time.Time
+
+	config     config.Config
+	configPath string
+
+	pages         pokecache.TypedCache[response]
+	areas         pokecache.TypedCache[area]
+	regions       pokecache.TypedCache[region]
+	encounters    pokecache.TypedCache[[]encounter]
+	locations     pokecache.TypedCache[location]
+	pokemons      pokecache.TypedCache[pokemon]
+	species       pokecache.TypedCache[species]
+	generations   pokecache.TypedCache[generation]
+	regionalDexes pokecache.TypedCache[regionalPokedex]
+
+	trainers trainer.Store
+	trainer  string
+	state    state
+	pokedex  map[string]caughtPokemon
+	seen     map[int]bool
+
+	// mu serializes the requests of the HTTP server.
+	mu sync.Mutex
+
+	registry    Registry
+	stderr      io.Writer
+	vars        map[string]string
+	stopOnError bool
+	depth       int
+}
+
+// NewSession resumes the trainer of the last session from opts.DataDir.
+func NewSession(opts Options) (*Session, error) {
+	aliases := pokecache.NewAliases()
+	s := &Session{
+		Format:        opts.Format,
+		Style:         opts.Style,
+		client:        opts.Client,
+		cache:         opts.Cache,
+		aliases:       aliases,
+		index:         loadIndex(opts.IndexPath),
+		random:        opts.Random,
+		now:           opts.Now,
+		config:        opts.Config,
+		configPath:    opts.ConfigPath,
+		pages:         pokecache.NewTypedCache[response](opts.Cache, "page"),
+		areas:         pokecache.NewTypedCache[area](opts.Cache, "location-area"),
+		regions:       pokecache.NewTypedCache[region](opts.Cache, "region"),
+		encounters:    pokecache.NewTypedCache[[]encounter](opts.Cache, "encounters"),
+		locations:     pokecache.NewTypedCache[location](opts.Cache, "location"),
+		pokemons:      pokecache.NewTypedCache[pokemon](opts.Cache, "pokemon"),
+		species:       pokecache.NewTypedCache[species](opts.Cache, "pokemon-species"),
+		generations:   pokecache.NewTypedCache[generation](opts.Cache, "generation"),
+		regionalDexes: pokecache.NewTypedCache[regionalPokedex](opts.Cache, "pokedex"),
+		state: state{
+			Next:  opts.Client.PageURL("location-area", 0, opts.Config.PageSize),
+			Limit: opts.Config.PageSize,
+		},
+		registry: NewRegistry(),
+		stderr:   opts.Stderr,
+		vars:     map[string]string{},
+	}
+	if s.random == nil {
+		s.random = rand.NormFloat64
+	}
+	if s.now == nil {
+		s.now = time.Now
+	}
+	if s.stderr == nil {
+		s.stderr = os.Stderr
+	}
+	for _, c := range s.builtins() {
+		s.registry.Register(c)
+	}
+	s.trainers = trainer.NewStore(filepath.Join(opts.DataDir, "trainers"))
+	if err := s.loadActiveTrainer(filepath.Join(opts.DataDir, "journal.jsonl")); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Registry gives access to the commands, so callers can add their own.
+func (s *Session) Registry() Registry {
+	return s.registry
+}
+
+func (s *Session) render(w io.Writer, v any) error {
+	out := render.Renderer{Writer: w, Format: s.Format, Style: s.Style}
+	return out.Render(v)
+}
+
+func (s *Session) record(entry journal.Entry) {
+	entry.Time = s.now()
+	// A full disk shouldn't stop the game, only the record keeping.
+	if err := s.journal.Append(entry); err != nil {
+		fmt.Fprintln(os.Stderr, "Could not write to the journal:", err)
+	}
+}
+
+func lookup[T any](ctx context.Context, s *Session, typed pokecache.TypedCache[T], id, url string) (T, error) {
+	if val, ok := typed.Get(id); ok {
+		return val, nil
+	}
+	// An expired value is revalidated, so an unchanged resource isn't
+	// downloaded again.
+	stale, validators, ok := typed.GetStale(id)
+	res, err := s.client.GetConditional(ctx, url, validators.ETag, validators.LastModified)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	if ok && res.NotModified {
+		typed.Refresh(id)
+		return stale, nil
+	}
+	return decode(typed, id, res)
+}
+
+// decode keeps only the decoded value in the cache, the body is counted
+// against the limits but dropped.
+func decode[T any](typed pokecache.TypedCache[T], id string, res api.Response) (T, error) {
+	var val T
+	if err := json.Unmarshal(res.Body, &val); err != nil {
+		return val, err
+	}
+	typed.AddWithValidators(id, val, len(res.Body), pokecache.Validators{ETag: res.ETag, LastModified: res.LastModified})
+	return val, nil
+}
+
+// lookupResource caches resources under their numeric id, whichever name or
+// id was asked for, and remembers the name for the next lookup.
+func lookupResource[T resource](ctx context.Context, s *Session, typed pokecache.TypedCache[T], nameOrID string) (T, error) {
+	nameOrID = strings.ToLower(nameOrID)
+	if id, ok := s.aliases.Resolve(typed.Kind(), nameOrID); ok {
+		return lookup(ctx, s, typed, strconv.Itoa(id), s.client.ResourceURL(typed.Kind(), strconv.Itoa(id)))
+	}
+	var val T
+	res, err := s.client.GetConditional(ctx, s.client.ResourceURL(typed.Kind(), nameOrID), "", "")
+	if err != nil {
+		return val, err
+	}
+	if err := json.Unmarshal(res.Body, &val); err != nil {
+		return val, err
+	}
+	id, name := val.identity()
+	s.aliases.Learn(typed.Kind(), name, id)
+	return decode(typed, strconv.Itoa(id), res)
+}
+
+func (s *Session) page(ctx context.Context, target string) (response, error) {
+	return lookup(ctx, s, s.pages, api.PageKey(target), target)
+}
+
+func learnAliases(aliases pokecache.Aliases, response response) {
+	for _, result := range response.Results {
+		if kind, id, ok := api.ParseResourceURL(result.URL); ok {
+			aliases.Learn(kind, result.Name, id)
+		}
+	}
+}
+
+func pageCount(count, limit int) int {
+	return max(1, (count+limit-1)/limit)
+}
+
+func breadcrumb(names ...string) string {
+	parts := []string{}
+	for _, name := range names {
+		if name != "" {
+			parts = append(parts, name)
+		}
+	}
+	return strings.Join(parts, " > ")
+}
+
+func (s *Session) caughtNumbers() map[int]bool {
+	numbers := map[int]bool{}
+	for _, c := range s.pokedex {
+		if number, ok := nationalNumber(c.Species.URL); ok {
+			numbers[number] = true
+		}
+	}
+	return numbers
+}
+
+func (s *Session) generationOf(ctx context.Context) func(p pokemon) (int, error) {
+	return func(p pokemon) (int, error) {
+		speciesDetails, err := lookupResource(ctx, s, s.species, p.Species.Name)
+		if err != nil {
+			return 0, err
+		}
+		return generationNumber(speciesDetails.Generation.Name), nil
+	}
+}
@@ -0,0 +1,198 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/c00rni/pokedex/internal/journal"
+	"github.com/c00rni/pokedex/internal/trainer"
+)
+
+// savedFields are the parts of a pokemon the pokedex and inspect commands
+// read, the moves and sprites stay in the API cache.
+var savedFields = []string{"id", "name", "base_experience", "height", "weight", "species", "stats", "types"}
+
+func trimPokemon(p pokemon) (json.RawMessage, error) {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	kept := map[string]json.RawMessage{}
+	for _, name := range savedFields {
+		kept[name] = fields[name]
+	}
+	return json.Marshal(kept)
+}
+
+func (s *Session) useTrainer(profile trainer.Profile) error {
+	pokedex := map[string]caughtPokemon{}
+	for _, c := range profile.Caught {
+		p := pokemon{}
+		if err := json.Unmarshal(c.Pokemon, &p); err != nil {
+			return fmt.Errorf("The %v pokemon of %v is corrupted: %w", c.Key, profile.Name, err)
+		}
+		// Older profiles keyed some pokemon by the spelling used to catch them.
+		pokedex[p.Name] = caughtPokemon{pokemon: p, CaughtAt: c.CaughtAt}
+	}
+	seen := map[int]bool{}
+	for _, number := range profile.Seen {
+		seen[number] = true
+	}
+
+	s.trainer = profile.Name
+	s.pokedex = pokedex
+	s.seen = seen
+	s.state.Region = profile.Location.Region
+	s.state.Location = profile.Location.Location
+	s.state.Area = profile.Location.Area
+	s.journal = journal.Open(s.trainers.JournalPath(profile.Name))
+	return nil
+}
+
+func (s *Session) profile() (trainer.Profile, error) {
+	profile := trainer.Profile{
+		Name:     s.trainer,
+		Caught:   []trainer.Caught{},
+		Seen:     []int{},
+		Location: trainer.Location{Region: s.state.Region, Location: s.state.Location, Area: s.state.Area},
+	}
+	for key, c := range s.pokedex {
+		trimmed, err := trimPokemon(c.pokemon)
+		if err != nil {
+			return profile, err
+		}
+		profile.Caught = append(profile.Caught, trainer.Caught{Key: key, CaughtAt: c.CaughtAt, Pokemon: trimmed})
+	}
+	sort.Slice(profile.Caught, func(i, j int) bool {
+		a, b := profile.Caught[i], profile.Caught[j]
+		if !a.CaughtAt.Equal(b.CaughtAt) {
+			return a.CaughtAt.Before(b.CaughtAt)
+		}
+		return a.Key < b.Key
+	})
+	for number := range s.seen {
+		profile.Seen = append(profile.Seen, number)
+	}
+	sort.Ints(profile.Seen)
+	return profile, nil
+}
+
+func (s *Session) saveTrainer() {
+	profile, err := s.profile()
+	if err == nil {
+		err = s.trainers.Save(profile)
+	}
+	// Like the journal, a failed save shouldn't stop the game.
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Could not save the trainer:", err)
+	}
+}
+
+// Trainer is the name of the active trainer.
+func (s *Session) Trainer() string {
+	return s.trainer
+}
+
+func (s *Session) switchTrainer(name string) error {
+	profile, err := s.trainers.Load(name)
+	if err != nil {
+		return err
+	}
+	if err := s.useTrainer(profile); err != nil {
+		return err
+	}
+	return s.trainers.SetActive(name)
+}
+
+// loadActiveTrainer resumes the trainer of the last session, falling back to
+// the default trainer when that one is gone and creating it on the first run.
+func (s *Session) loadActiveTrainer(legacyJournal string) error {
+	err := s.switchTrainer(s.trainers.Active())
+	if !errors.Is(err, trainer.ErrNotFound) {
+		return err
+	}
+	err = s.switchTrainer(trainer.DefaultName)
+	if !errors.Is(err, trainer.ErrNotFound) {
+		return err
+	}
+	// Journals used to be shared by everyone, they now belong to the default
+	// trainer. Moving it before the trainer exists tries again on the next
+	// start when it fails.
+	if _, err := os.Stat(legacyJournal); err == nil {
+		journalPath := s.trainers.JournalPath(trainer.DefaultName)
+		err := os.MkdirAll(filepath.Dir(journalPath), 0o755)
+		if err == nil {
+			err = os.Rename(legacyJournal, journalPath)
+		}
+		if err != nil {
+			return fmt.Errorf("Could not move the old journal to the default trainer: %w", err)
+		}
+	}
+	if _, err := s.trainers.Create(trainer.DefaultName); err != nil {
+		return err
+	}
+	return s.switchTrainer(trainer.DefaultName)
+}
+
+func (s *Session) newTrainerResult() trainerResult {
+	return trainerResult{
+		Name:     s.trainer,
+		Location: breadcrumb(s.state.Region, s.state.Location, s.state.Area),
+		Caught:   len(s.pokedex),
+		Seen:     len(s.seen),
+	}
+}
+
+func (s *Session) commandTrainer(_ context.Context, w io.Writer, args []string) error {
+	if len(args) == 0 {
+		return s.render(w, s.newTrainerResult())
+	}
+	switch {
+	case args[0] == "list" && len(args) == 1:
+		names, err := s.trainers.List()
+		if err != nil {
+			return err
+		}
+		result := listing{Title: "Trainers:", Items: []string{}}
+		for _, name := range names {
+			if name == s.trainer {
+				name += " (active)"
+			}
+			result.Items = append(result.Items, name)
+		}
+		return s.render(w, result)
+	case args[0] == "new" && len(args) == 2:
+		_, err := s.trainers.Create(args[1])
+		if errors.Is(err, trainer.ErrExists) {
+			return fmt.Errorf("There is already a trainer named %v, switch to it with trainer switch %v", args[1], args[1])
+		}
+		if err != nil {
+			return err
+		}
+		if err := s.switchTrainer(args[1]); err != nil {
+			return err
+		}
+		return s.render(w, message{Message: fmt.Sprintf("Welcome, %v! You are starting your journey.", args[1])})
+	case args[0] == "switch" && len(args) == 2:
+		err := s.switchTrainer(args[1])
+		if errors.Is(err, trainer.ErrNotFound) {
+			return fmt.Errorf("There is no trainer named %v, create one with trainer new %v", args[1], args[1])
+		}
+		if err != nil {
+			return err
+		}
+		return s.render(w, s.newTrainerResult())
+	default:
+		return errors.New("Usage: trainer [list | new <name> | switch <name>]")
+	}
+}
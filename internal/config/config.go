@@ -0,0 +1,338 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/c00rni/pokedex/internal/api"
+	"github.com/c00rni/pokedex/internal/pokecache"
+)
+
+// Where a setting comes from, later sources override earlier ones.
+const (
+	SourceDefault = "default"
+	SourceFile    = "file"
+	SourceEnv     = "env"
+	SourceFlag    = "flag"
+	// SourceSession is a change made with no config file to save it to.
+	SourceSession = "session"
+)
+
+type Config struct {
+	APIURL   string
+	Timeout  time.Duration
+	PageSize int
+	CacheTTL time.Duration
+	// CacheMaxEntries and CacheMaxBytes bound the cache, zero lifts a limit.
+	CacheMaxEntries int
+	CacheMaxBytes   int
+	// CatchThreshold is what the base experience of a pokemon times a normal
+	// roll has to stay under for a catch, raise it to catch more often.
+	CatchThreshold float64
+
+	sources map[string]string
+}
+
+type key struct {
+	name string
+	// quoted settings are TOML strings, the others bare numbers.
+	quoted bool
+	// restart is true for settings read once, when the Pokedex starts.
+	restart bool
+	get     func(c Config) string
+	set     func(c *Config, value string) error
+}
+
+var keys = []key{
+	{
+		name: "api_url", quoted: true, restart: true,
+		get: func(c Config) string { return c.APIURL },
+		set: func(c *Config, value string) error {
+			parsed, err := url.Parse(value)
+			if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+				return fmt.Errorf("The api_url setting needs an http or https URL, got %q", value)
+			}
+			if !strings.HasSuffix(value, "/") {
+				value += "/"
+			}
+			c.APIURL = value
+			return nil
+		},
+	},
+	{
+		name: "timeout", quoted: true, restart: true,
+		get: func(c Config) string { return c.Timeout.String() },
+		set: func(c *Config, value string) error {
+			return parseDuration("timeout", value, &c.Timeout)
+		},
+	},
+	{
+		name: "page_size",
+		get:  func(c Config) string { return strconv.Itoa(c.PageSize) },
+		set: func(c *Config, value string) error {
+			size, err := strconv.Atoi(value)
+			if err != nil || size < 1 {
+				return fmt.Errorf("The page_size setting needs a positive number, got %q", value)
+			}
+			c.PageSize = size
+			return nil
+		},
+	},
+	{
+		name: "cache_ttl", quoted: true, restart: true,
+		get: func(c Config) string { return c.CacheTTL.String() },
+		set: func(c *Config, value string) error {
+			return parseDuration("cache_ttl", value, &c.CacheTTL)
+		},
+	},
+	{
+		name: "cache_max_entries", restart: true,
+		get: func(c Config) string { return strconv.Itoa(c.CacheMaxEntries) },
+		set: func(c *Config, value string) error {
+			return parseLimit("cache_max_entries", value, &c.CacheMaxEntries)
+		},
+	},
+	{
+		name: "cache_max_bytes", restart: true,
+		get: func(c Config) string { return strconv.Itoa(c.CacheMaxBytes) },
+		set: func(c *Config, value string) error {
+			return parseLimit("cache_max_bytes", value, &c.CacheMaxBytes)
+		},
+	},
+	{
+		name: "catch_threshold",
+		get:  func(c Config) string { return strconv.FormatFloat(c.CatchThreshold, 'g', -1, 64) },
+		set: func(c *Config, value string) error {
+			threshold, err := strconv.ParseFloat(value, 64)
+			if err != nil || threshold <= 0 {
+				return fmt.Errorf("The catch_threshold setting needs a positive number, got %q", value)
+			}
+			c.CatchThreshold = threshold
+			return nil
+		},
+	},
+}
+
+func parseDuration(name, value string, d *time.Duration) error {
+	parsed, err := time.ParseDuration(value)
+	if err != nil || parsed <= 0 {
+		return fmt.Errorf("The %v setting needs a duration such as 30s or 5m, got %q", name, value)
+	}
+	*d = parsed
+	return nil
+}
+
+func parseLimit(name, value string, limit *int) error {
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 0 {
+		return fmt.Errorf("The %v setting needs a number, 0 for no limit, got %q", name, value)
+	}
+	*limit = parsed
+	return nil
+}
+
+func lookupKey(name string) (key, error) {
+	for _, k := range keys {
+		if k.name == name {
+			return k, nil
+		}
+	}
+	return key{}, fmt.Errorf("Unknown setting %q, use one of %v", name, strings.Join(Keys(), ", "))
+}
+
+// Keys lists the settings in the order they are documented.
+func Keys() []string {
+	names := []string{}
+	for _, k := range keys {
+		names = append(names, k.name)
+	}
+	return names
+}
+
+// EnvName is the variable overriding a setting, POKEDEX_API_URL for api_url.
+func EnvName(name string) string {
+	return "POKEDEX_" + strings.ToUpper(name)
+}
+
+// FlagName is the command line option overriding a setting, --api-url for
+// api_url.
+func FlagName(name string) string {
+	return "--" + strings.ReplaceAll(name, "_", "-")
+}
+
+// NeedsRestart reports whether a setting is only read when the Pokedex starts.
+func NeedsRestart(name string) bool {
+	k, err := lookupKey(name)
+	return err == nil && k.restart
+}
+
+func Default() Config {
+	c := Config{
+		APIURL:          api.DefaultBaseURL,
+		Timeout:         10 * time.Second,
+		PageSize:        20,
+		CacheTTL:        time.Minute,
+		CacheMaxEntries: pokecache.DefaultLimits.MaxEntries,
+		CacheMaxBytes:   pokecache.DefaultLimits.MaxBytes,
+		CatchThreshold:  10,
+		sources:         map[string]string{},
+	}
+	for _, k := range keys {
+		c.sources[k.name] = SourceDefault
+	}
+	return c
+}
+
+func (c Config) Get(name string) (string, error) {
+	k, err := lookupKey(name)
+	if err != nil {
+		return "", err
+	}
+	return k.get(c), nil
+}
+
+func (c Config) Source(name string) string {
+	return c.sources[name]
+}
+
+func (c *Config) Set(name, value, source string) error {
+	k, err := lookupKey(name)
+	if err != nil {
+		return err
+	}
+	if err := k.set(c, value); err != nil {
+		return err
+	}
+	sources := map[string]string{}
+	for other, from := range c.sources {
+		sources[other] = from
+	}
+	sources[name] = source
+	c.sources = sources
+	return nil
+}
+
+// ApplyEnv overrides the settings with the POKEDEX_* variables found by
+// lookup, os.LookupEnv outside of tests.
+func (c *Config) ApplyEnv(lookup func(string) (string, bool)) error {
+	for _, k := range keys {
+		value, ok := lookup(EnvName(k.name))
+		if !ok || value == "" {
+			continue
+		}
+		if err := c.Set(k.name, value, SourceEnv); err != nil {
+			return fmt.Errorf("%v: %w", EnvName(k.name), err)
+		}
+	}
+	return nil
+}
+
+// parseLine reads a `name = value` line of the flat TOML subset the config
+// file uses. Blank lines and comments have no name, and tables don't exist
+// in a flat file.
+func parseLine(line string) (string, string, error) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", "", nil
+	}
+	if strings.HasPrefix(line, "[") {
+		return "", "", fmt.Errorf("tables are not supported, put the settings at the top level instead of %q", line)
+	}
+	name, value, ok := strings.Cut(line, "=")
+	if !ok {
+		return "", "", fmt.Errorf("expected name = value, got %q", line)
+	}
+	name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+	if strings.HasPrefix(value, `"`) {
+		end := strings.LastIndex(value, `"`)
+		if end == 0 {
+			return "", "", fmt.Errorf("unterminated string in %q", line)
+		}
+		unquoted, err := strconv.Unquote(value[:end+1])
+		if err != nil {
+			return "", "", fmt.Errorf("invalid string in %q", line)
+		}
+		return name, unquoted, nil
+	}
+	value, _, _ = strings.Cut(value, "#")
+	return name, strings.TrimSpace(value), nil
+}
+
+// Load reads the config file over the defaults, a missing file leaves them
+// as they are.
+func Load(path string) (Config, error) {
+	c := Default()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return c, err
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for number := 1; scanner.Scan(); number++ {
+		name, value, err := parseLine(scanner.Text())
+		if err == nil && name != "" {
+			err = c.Set(name, value, SourceFile)
+		}
+		if err != nil {
+			return c, fmt.Errorf("%v:%v: %w", path, number, err)
+		}
+	}
+	return c, scanner.Err()
+}
+
+// Save writes one setting to the config file, keeping the other lines and
+// their comments as they are.
+func Save(path, name, value string) error {
+	k, err := lookupKey(name)
+	if err != nil {
+		return err
+	}
+	validated := Default()
+	if err := k.set(&validated, value); err != nil {
+		return err
+	}
+	line := fmt.Sprintf("%v = %v", name, k.get(validated))
+	if k.quoted {
+		line = fmt.Sprintf("%v = %q", name, k.get(validated))
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	lines := []string{}
+	if len(data) > 0 {
+		lines = strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	}
+	// Load keeps the last of duplicated settings, so they all get the value.
+	replaced := false
+	for i, existing := range lines {
+		if current, _, err := parseLine(existing); err == nil && current == name {
+			lines[i] = line
+			replaced = true
+		}
+	}
+	if !replaced {
+		lines = append(lines, line)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
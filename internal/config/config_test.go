@@ -0,0 +1,137 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoad(t *testing.T) {
+	cases := []struct {
+		file     string
+		expected Config
+		err      string
+	}{
+		{
+			file:     "",
+			expected: Config{APIURL: "https://pokeapi.co/api/v2/", Timeout: 10 * time.Second, PageSize: 20, CacheTTL: time.Minute, CacheMaxEntries: 500, CacheMaxBytes: 32 << 20, CatchThreshold: 10},
+		},
+		{
+			file:     "# A local mirror\napi_url = \"http://localhost:8000/api/v2\" # no trailing slash\npage_size = 5\ncache_ttl = \"5m\"\ncache_max_entries = 50\n",
+			expected: Config{APIURL: "http://localhost:8000/api/v2/", Timeout: 10 * time.Second, PageSize: 5, CacheTTL: 5 * time.Minute, CacheMaxEntries: 50, CacheMaxBytes: 32 << 20, CatchThreshold: 10},
+		},
+		{
+			file: "page_size = 0\n",
+			err:  "config.toml:1: The page_size setting needs a positive number",
+		},
+		{
+			file: "colour = \"red\"\n",
+			err:  "Unknown setting \"colour\"",
+		},
+		{
+			file: "api_url\n",
+			err:  "expected name = value",
+		},
+		{
+			file: "[api]\ntimeout = \"5s\"\n",
+			err:  "config.toml:1: tables are not supported",
+		},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("Test case %v", i), func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.toml")
+			if c.file != "" {
+				if err := os.WriteFile(path, []byte(c.file), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			config, err := Load(path)
+			if c.err != "" {
+				if err == nil || !strings.Contains(err.Error(), c.err) {
+					t.Errorf("expected an error containing %q, got %v", c.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("expected no error, got %v", err)
+				return
+			}
+			config.sources = nil
+			if !reflect.DeepEqual(config, c.expected) {
+				t.Errorf("expected %+v, got %+v", c.expected, config)
+			}
+		})
+	}
+}
+
+func TestApplyEnv(t *testing.T) {
+	config := Default()
+	env := map[string]string{"POKEDEX_API_URL": "https://mirror.example/api/v2/", "POKEDEX_CACHE_TTL": "30s", "POKEDEX_PAGE_SIZE": ""}
+	err := config.ApplyEnv(func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	})
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if config.APIURL != "https://mirror.example/api/v2/" || config.CacheTTL != 30*time.Second || config.PageSize != 20 {
+		t.Errorf("expected the env overrides, got %+v", config)
+	}
+	if config.Source("cache_ttl") != SourceEnv || config.Source("page_size") != SourceDefault {
+		t.Errorf("expected cache_ttl from env and page_size by default, got %v and %v", config.Source("cache_ttl"), config.Source("page_size"))
+	}
+
+	env = map[string]string{"POKEDEX_TIMEOUT": "soon"}
+	err = config.ApplyEnv(func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	})
+	if err == nil || !strings.HasPrefix(err.Error(), "POKEDEX_TIMEOUT: ") {
+		t.Errorf("expected the variable in the error, got %v", err)
+	}
+}
+
+func TestSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pokedex", "config.toml")
+	if err := Save(path, "page_size", "10"); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	original := "# Self-hosted PokeAPI\napi_url = \"http://localhost:8000/api/v2/\"\npage_size = 10\n"
+	if err := os.WriteFile(path, []byte(original), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := Save(path, "api_url", "http://pokeapi.lan/api/v2"); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if err := Save(path, "catch_threshold", "25"); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if err := Save(path, "timeout", "never"); err == nil {
+		t.Errorf("expected an invalid timeout to be refused")
+	}
+
+	data, _ := os.ReadFile(path)
+	expected := "# Self-hosted PokeAPI\napi_url = \"http://pokeapi.lan/api/v2/\"\npage_size = 10\ncatch_threshold = 25\n"
+	if string(data) != expected {
+		t.Errorf("expected %q, got %q", expected, data)
+	}
+	config, err := Load(path)
+	if err != nil || config.CatchThreshold != 25 || config.Source("catch_threshold") != SourceFile {
+		t.Errorf("expected the saved file to load, got %+v and %v", config, err)
+	}
+
+	if err := os.WriteFile(path, []byte("page_size = 10\npage_size = 30\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := Save(path, "page_size", "15"); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if config, err := Load(path); err != nil || config.PageSize != 15 {
+		t.Errorf("expected every duplicate to get the saved page size, got %v and %v", config.PageSize, err)
+	}
+}
@@ -0,0 +1,176 @@
+// Package fakeapi serves a small slice of PokeAPI from embedded fixtures, for
+// tests and for working offline.
+//
+// Fixtures live in resources/<kind>/<name>.json and keep the links of the
+// real API, which the server rewrites to its own address. List endpoints
+// such as /api/v2/location-area/?offset=0&limit=20 are built from the
+// fixtures of a kind, sorted by id, and /api/v2/pokemon/<id>/encounters
+// serves encounters/<name>.json.
+package fakeapi
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/c00rni/pokedex/internal/api"
+)
+
+//go:embed resources encounters
+var fixtures embed.FS
+
+const apiPath = "/api/v2/"
+
+type resource struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	body []byte
+}
+
+type Handler struct {
+	kinds map[string][]resource
+}
+
+// NewHandler loads the embedded fixtures, it panics if one of them is not
+// valid JSON with an id and a name since that is a bug in the package.
+func NewHandler() Handler {
+	h := Handler{kinds: map[string][]resource{}}
+	err := fs.WalkDir(fixtures, "resources", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		body, err := fixtures.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		res := resource{body: body}
+		if err := json.Unmarshal(body, &res); err != nil {
+			return fmt.Errorf("%v: %w", name, err)
+		}
+		kind := path.Base(path.Dir(name))
+		h.kinds[kind] = append(h.kinds[kind], res)
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+	for _, resources := range h.kinds {
+		sort.Slice(resources, func(i, j int) bool { return resources[i].ID < resources[j].ID })
+	}
+	return h
+}
+
+func (h Handler) find(kind, nameOrID string) (resource, bool) {
+	for _, res := range h.kinds[kind] {
+		if res.Name == nameOrID || strconv.Itoa(res.ID) == nameOrID {
+			return res, true
+		}
+	}
+	return resource{}, false
+}
+
+func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	rest, ok := strings.CutPrefix(r.URL.Path, apiPath)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	parts := strings.Split(strings.Trim(rest, "/"), "/")
+	var body []byte
+	switch {
+	case len(parts) == 1 && h.kinds[parts[0]] != nil:
+		body = h.list(parts[0], r)
+	case len(parts) == 2:
+		res, ok := h.find(parts[0], strings.ToLower(parts[1]))
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		body = res.body
+	case len(parts) == 3 && parts[0] == "pokemon" && parts[2] == "encounters":
+		res, ok := h.find("pokemon", parts[1])
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		body, _ = fixtures.ReadFile("encounters/" + res.Name + ".json")
+	}
+	if body == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	body = bytes.ReplaceAll(body, []byte(api.DefaultBaseURL), []byte("http://"+r.Host+apiPath))
+	etag := fmt.Sprintf(`"%x"`, sha1.Sum(body))
+	w.Header().Set("ETag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body)
+}
+
+type listResponse struct {
+	Count    int       `json:"count"`
+	Next     *string   `json:"next"`
+	Previous *string   `json:"previous"`
+	Results  []listRef `json:"results"`
+}
+
+type listRef struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+func (h Handler) list(kind string, r *http.Request) []byte {
+	offset, limit := api.ParsePage(r.URL.String())
+	resources := h.kinds[kind]
+	page := func(offset int) *string {
+		link := fmt.Sprintf("%v%v/?offset=%d&limit=%d", api.DefaultBaseURL, kind, offset, limit)
+		return &link
+	}
+
+	response := listResponse{Count: len(resources), Results: []listRef{}}
+	for i := offset; i >= 0 && i < len(resources) && i < offset+limit; i++ {
+		response.Results = append(response.Results, listRef{
+			Name: resources[i].Name,
+			URL:  fmt.Sprintf("%v%v/%d/", api.DefaultBaseURL, kind, resources[i].ID),
+		})
+	}
+	if offset+limit < len(resources) {
+		response.Next = page(offset + limit)
+	}
+	if offset > 0 {
+		response.Previous = page(max(0, offset-limit))
+	}
+	body, _ := json.Marshal(response)
+	return body
+}
+
+// Server runs the fixtures on a local port, Close it when done.
+type Server struct {
+	*httptest.Server
+}
+
+func NewServer() Server {
+	return Server{httptest.NewServer(NewHandler())}
+}
+
+// BaseURL is the API root to give to api.NewClient.
+func (s Server) BaseURL() string {
+	return s.URL + apiPath
+}
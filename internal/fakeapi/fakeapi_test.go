@@ -0,0 +1,122 @@
+package fakeapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/c00rni/pokedex/internal/api"
+)
+
+func TestListPagination(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	client := api.NewClient(server.BaseURL(), time.Second)
+
+	cases := []struct {
+		offset   int
+		names    []string
+		next     string
+		previous string
+	}{
+		{offset: 0, names: []string{"canalave-city-area", "eterna-city-area"}, next: client.PageURL("location-area", 2, 2)},
+		{offset: 2, names: []string{"pastoria-city-area", "sunyshore-city-area"}, next: client.PageURL("location-area", 4, 2), previous: client.PageURL("location-area", 0, 2)},
+		{offset: 4, names: []string{"sinnoh-pokemon-league-area"}, previous: client.PageURL("location-area", 2, 2)},
+		{offset: 10, names: []string{}, previous: client.PageURL("location-area", 8, 2)},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("Test case %v", i), func(t *testing.T) {
+			body, err := client.Get(context.Background(), client.PageURL("location-area", c.offset, 2))
+			if err != nil {
+				t.Errorf("expected no error, got %v", err)
+				return
+			}
+			page := struct {
+				Count    int
+				Next     string
+				Previous string
+				Results  []struct{ Name string }
+			}{}
+			if err := json.Unmarshal(body, &page); err != nil {
+				t.Errorf("expected a JSON page, got %v", err)
+				return
+			}
+			names := []string{}
+			for _, result := range page.Results {
+				names = append(names, result.Name)
+			}
+			if page.Count != 5 || strings.Join(names, ",") != strings.Join(c.names, ",") {
+				t.Errorf("expected 5 areas with %v on the page, got %v with %v", c.names, page.Count, names)
+			}
+			if page.Next != c.next || page.Previous != c.previous {
+				t.Errorf("expected next %q and previous %q, got %q and %q", c.next, c.previous, page.Next, page.Previous)
+			}
+		})
+	}
+}
+
+func TestResources(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	client := api.NewClient(server.BaseURL(), time.Second)
+
+	cases := []struct {
+		url      string
+		contains string
+		err      error
+	}{
+		{url: client.ResourceURL("pokemon", "pikachu"), contains: `"name": "pikachu"`},
+		{url: client.ResourceURL("pokemon", "25"), contains: `"name": "pikachu"`},
+		{url: client.ResourceURL("pokemon", "Pikachu"), contains: server.BaseURL() + "pokemon/25/encounters"},
+		{url: client.ResourceURL("pokemon", "25") + "encounters", contains: "trophy-garden-area"},
+		{url: client.ResourceURL("pokemon-species", "buizel"), contains: "generation-iv"},
+		{url: client.ResourceURL("type", "electric"), contains: "thunder-shock"},
+		{url: client.ResourceURL("move", "water-gun"), contains: `"power": 40`},
+		{url: client.ResourceURL("pokemon", "missingno"), err: api.ErrNotFound},
+		{url: client.ResourceURL("berry", "cheri"), err: api.ErrNotFound},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("Test case %v", i), func(t *testing.T) {
+			body, err := client.Get(context.Background(), c.url)
+			if c.err != nil {
+				if !errors.Is(err, c.err) {
+					t.Errorf("expected %v, got %v", c.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("expected no error, got %v", err)
+				return
+			}
+			if !strings.Contains(string(body), c.contains) {
+				t.Errorf("expected %v to contain %q", c.url, c.contains)
+			}
+			if strings.Contains(string(body), api.DefaultBaseURL) {
+				t.Errorf("expected links of %v to point to the fake server", c.url)
+			}
+		})
+	}
+}
+
+func TestConditionalRequests(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	client := api.NewClient(server.BaseURL(), time.Second)
+	url := client.ResourceURL("region", "sinnoh")
+
+	first, err := client.GetConditional(context.Background(), url, "", "")
+	if err != nil || first.ETag == "" {
+		t.Errorf("expected a response with an ETag, got %v", err)
+		return
+	}
+	second, err := client.GetConditional(context.Background(), url, first.ETag, "")
+	if err != nil || !second.NotModified {
+		t.Errorf("expected the resource not to be modified, got %v", err)
+	}
+}
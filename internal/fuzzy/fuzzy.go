@@ -0,0 +1,84 @@
+package fuzzy
+
+import (
+	"sort"
+	"strings"
+)
+
+func Distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// maxEdits is how many typos a query may have, more for longer ones.
+func maxEdits(query string) int {
+	return max(2, len(query)/3)
+}
+
+func Closest(query string, candidates []string, limit int) []string {
+	query = strings.ToLower(query)
+	threshold := maxEdits(query)
+
+	type match struct {
+		name     string
+		distance int
+	}
+	matches := []match{}
+	for _, candidate := range candidates {
+		distance := Distance(query, strings.ToLower(candidate))
+		if distance <= threshold {
+			matches = append(matches, match{name: candidate, distance: distance})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].distance != matches[j].distance {
+			return matches[i].distance < matches[j].distance
+		}
+		return matches[i].name < matches[j].name
+	})
+
+	names := []string{}
+	for i := 0; i < len(matches) && i < limit; i++ {
+		names = append(names, matches[i].name)
+	}
+	return names
+}
+
+// Score ranks how well a candidate matches a query: exact matches beat
+// prefixes, prefixes beat substrings and substrings beat typos. Zero means no
+// match at all.
+func Score(query, candidate string) int {
+	query, candidate = strings.ToLower(query), strings.ToLower(candidate)
+	switch {
+	case query == "":
+		return 0
+	case candidate == query:
+		return 100
+	case strings.HasPrefix(candidate, query):
+		return 80 - min(len(candidate)-len(query), 19)
+	case strings.Contains(candidate, query):
+		return 60 - min(len(candidate)-len(query), 19)
+	}
+	// Typos share the scores under 41, every allowed distance keeping one.
+	distance, threshold := Distance(query, candidate), maxEdits(query)
+	if distance > threshold {
+		return 0
+	}
+	return max(1, 40*(threshold+1-distance)/(threshold+1))
+}
@@ -0,0 +1,58 @@
+package fuzzy
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestDistance(t *testing.T) {
+	cases := []struct {
+		a, b     string
+		expected int
+	}{
+		{a: "pikachu", b: "pikachu", expected: 0},
+		{a: "pikachuu", b: "pikachu", expected: 1},
+		{a: "bulbsaur", b: "bulbasaur", expected: 1},
+		{a: "", b: "eevee", expected: 5},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("Test case %v", i), func(t *testing.T) {
+			if got := Distance(c.a, c.b); got != c.expected {
+				t.Errorf("expected %v, got %v", c.expected, got)
+			}
+		})
+	}
+}
+
+func TestClosest(t *testing.T) {
+	names := []string{"pichu", "pikachu", "raichu", "charmander"}
+	got := Closest("pikachuu", names, 2)
+	if len(got) != 1 || got[0] != "pikachu" {
+		t.Errorf("expected [pikachu], got %v", got)
+	}
+	if got := Closest("zzzzzz", names, 2); len(got) != 0 {
+		t.Errorf("expected no suggestions, got %v", got)
+	}
+}
+
+func TestScoreRanking(t *testing.T) {
+	exact := Score("pikachu", "pikachu")
+	prefix := Score("pika", "pikachu")
+	substring := Score("chu", "pikachu")
+	typo := Score("pikachuu", "pikachu")
+	if !(exact > prefix && prefix > substring && substring > typo && typo > 0) {
+		t.Errorf("unexpected ranking %v, %v, %v, %v", exact, prefix, substring, typo)
+	}
+	// Long queries allow more typos, up to 4 for 12 letters.
+	if got := Distance("krabomenabel", "crabominable"); got != 4 {
+		t.Errorf("expected 4 edits, got %v", got)
+	}
+	far, near := Score("krabomenabel", "crabominable"), Score("crabominabel", "crabominable")
+	if !(far > 0 && near > far && substring > near) {
+		t.Errorf("unexpected ranking of long typos %v, %v", near, far)
+	}
+	if got := Score("zzzzzz", "pikachu"); got != 0 {
+		t.Errorf("expected no match, got %v", got)
+	}
+}
@@ -0,0 +1,159 @@
+package journal
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	KindCatch   = "catch"
+	KindExplore = "explore"
+
+	OutcomeCaught  = "caught"
+	OutcomeEscaped = "escaped"
+)
+
+type Entry struct {
+	Time    time.Time `json:"time"`
+	Kind    string    `json:"kind"`
+	Pokemon string    `json:"pokemon,omitempty"`
+	Area    string    `json:"area,omitempty"`
+	Outcome string    `json:"outcome,omitempty"`
+	Ball    string    `json:"ball,omitempty"`
+}
+
+type Filter struct {
+	Since time.Time
+	Kind  string
+}
+
+func (f Filter) match(entry Entry) bool {
+	if !f.Since.IsZero() && entry.Time.Before(f.Since) {
+		return false
+	}
+	return f.Kind == "" || entry.Kind == f.Kind
+}
+
+// Journal is an append-only JSON Lines log of what happened in a session.
+type Journal struct {
+	path string
+	mu   *sync.Mutex
+}
+
+func Open(path string) Journal {
+	return Journal{
+		path: path,
+		mu:   &sync.Mutex{},
+	}
+}
+
+func (j Journal) Append(entry Entry) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(j.path), 0o755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(j.path, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	// A process that died mid-append leaves a torn last line, start on a
+	// fresh one so this entry doesn't get glued to it.
+	if info, err := file.Stat(); err == nil && info.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := file.ReadAt(last, info.Size()-1); err == nil && last[0] != '\n' {
+			line = append([]byte{'\n'}, line...)
+		}
+	}
+	_, err = file.Write(append(line, '\n'))
+	return err
+}
+
+func (j Journal) Read(filter Filter) ([]Entry, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	entries := []Entry{}
+	file, err := os.Open(j.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		entry := Entry{}
+		// Skip the lines that don't decode, such as one torn by a crash, the
+		// rest of the journal is still worth reading.
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		if filter.match(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, scanner.Err()
+}
+
+type CatchRate struct {
+	Key      string `json:"key"`
+	Attempts int    `json:"attempts"`
+	Caught   int    `json:"caught"`
+}
+
+func (r CatchRate) Rate() float64 {
+	if r.Attempts == 0 {
+		return 0
+	}
+	return float64(r.Caught) / float64(r.Attempts)
+}
+
+// CatchRates groups catch attempts by the key returned for each entry, such
+// as the species or the ball, most attempted first.
+func CatchRates(entries []Entry, key func(Entry) string) []CatchRate {
+	byKey := map[string]*CatchRate{}
+	for _, entry := range entries {
+		if entry.Kind != KindCatch {
+			continue
+		}
+		k := key(entry)
+		rate, ok := byKey[k]
+		if !ok {
+			rate = &CatchRate{Key: k}
+			byKey[k] = rate
+		}
+		rate.Attempts++
+		if entry.Outcome == OutcomeCaught {
+			rate.Caught++
+		}
+	}
+
+	rates := []CatchRate{}
+	for _, rate := range byKey {
+		rates = append(rates, *rate)
+	}
+	sort.Slice(rates, func(i, j int) bool {
+		if rates[i].Attempts != rates[j].Attempts {
+			return rates[i].Attempts > rates[j].Attempts
+		}
+		return rates[i].Key < rates[j].Key
+	})
+	return rates
+}
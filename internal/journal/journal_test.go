@@ -0,0 +1,83 @@
+package journal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAppendAndRead(t *testing.T) {
+	j := Open(filepath.Join(t.TempDir(), "journal.jsonl"))
+	now := time.Now()
+	entries := []Entry{
+		{Time: now.Add(-2 * time.Hour), Kind: KindExplore, Area: "pallet-town-area"},
+		{Time: now.Add(-time.Minute), Kind: KindCatch, Pokemon: "pidgey", Outcome: OutcomeCaught, Ball: "poke-ball"},
+		{Time: now, Kind: KindCatch, Pokemon: "pidgey", Outcome: OutcomeEscaped, Ball: "poke-ball"},
+	}
+	for _, entry := range entries {
+		if err := j.Append(entry); err != nil {
+			t.Errorf("expected no error, got %v", err)
+			return
+		}
+	}
+
+	cases := []struct {
+		filter   Filter
+		expected int
+	}{
+		{filter: Filter{}, expected: 3},
+		{filter: Filter{Kind: KindCatch}, expected: 2},
+		{filter: Filter{Since: now.Add(-time.Hour)}, expected: 2},
+	}
+	for _, c := range cases {
+		got, err := j.Read(c.filter)
+		if err != nil {
+			t.Errorf("expected no error, got %v", err)
+			continue
+		}
+		if len(got) != c.expected {
+			t.Errorf("expected %v entries for %+v, got %v", c.expected, c.filter, len(got))
+		}
+	}
+}
+
+func TestReadSkipsTornLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	torn := `{"time":"2024-05-01T12:00:00Z","kind":"explore","area":"pallet-town-area"}` + "\nnot json\n" + `{"time":"2024-05-01T12:01:00Z","kind":"ca`
+	if err := os.WriteFile(path, []byte(torn), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	j := Open(path)
+	if err := j.Append(Entry{Kind: KindCatch, Pokemon: "pidgey", Outcome: OutcomeCaught}); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	got, err := j.Read(Filter{})
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+		return
+	}
+	if len(got) != 2 || got[0].Area != "pallet-town-area" || got[1].Pokemon != "pidgey" {
+		t.Errorf("expected the entries around the torn lines, got %+v", got)
+	}
+}
+
+func TestReadMissingJournal(t *testing.T) {
+	got, err := Open(filepath.Join(t.TempDir(), "missing.jsonl")).Read(Filter{})
+	if err != nil || len(got) != 0 {
+		t.Errorf("expected an empty journal, got %v, %v", got, err)
+	}
+}
+
+func TestCatchRates(t *testing.T) {
+	entries := []Entry{
+		{Kind: KindCatch, Pokemon: "pidgey", Outcome: OutcomeCaught},
+		{Kind: KindCatch, Pokemon: "pidgey", Outcome: OutcomeEscaped},
+		{Kind: KindCatch, Pokemon: "rattata", Outcome: OutcomeCaught},
+		{Kind: KindExplore, Area: "pallet-town-area"},
+	}
+	rates := CatchRates(entries, func(e Entry) string { return e.Pokemon })
+	if len(rates) != 2 || rates[0].Key != "pidgey" || rates[0].Rate() != 0.5 {
+		t.Errorf("unexpected rates %+v", rates)
+	}
+}
@@ -0,0 +1,37 @@
+package pokecache
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Aliases maps resource names to their numeric ids so that every spelling of
+// a resource resolves to the same cache key.
+type Aliases struct {
+	mu  *sync.RWMutex
+	ids map[string]int
+}
+
+func NewAliases() Aliases {
+	return Aliases{
+		mu:  &sync.RWMutex{},
+		ids: map[string]int{},
+	}
+}
+
+func (a Aliases) Learn(kind, name string, id int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.ids[kind+"/"+strings.ToLower(name)] = id
+}
+
+func (a Aliases) Resolve(kind, nameOrID string) (int, bool) {
+	if id, err := strconv.Atoi(nameOrID); err == nil {
+		return id, true
+	}
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	id, ok := a.ids[kind+"/"+strings.ToLower(nameOrID)]
+	return id, ok
+}
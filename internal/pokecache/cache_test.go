@@ -25,6 +25,7 @@ func TestAddGet(t *testing.T) {
 	for i, c := range cases {
 		t.Run(fmt.Sprintf("Test case %v", i), func(t *testing.T) {
 			cache := NewCache(interval)
+			defer cache.Close()
 			cache.Add(c.key, c.val)
 			val, ok := cache.Get(c.key)
 			if !ok {
@@ -39,10 +40,24 @@ func TestAddGet(t *testing.T) {
 	}
 }
 
+type fakeClock struct {
+	now time.Time
+}
+
+func (f *fakeClock) Now() time.Time {
+	return f.now
+}
+
+func (f *fakeClock) Advance(d time.Duration) {
+	f.now = f.now.Add(d)
+}
+
 func TestReapLoop(t *testing.T) {
-	const baseTime = 5 * time.Millisecond
-	const waitTime = baseTime + 5*time.Millisecond
-	cache := NewCache(baseTime)
+	const baseTime = 5 * time.Minute
+	const waitTime = baseTime + 5*time.Minute
+	clock := &fakeClock{now: time.Now()}
+	cache := NewCacheWithClock(baseTime, Limits{}, clock)
+	defer cache.Close()
 	cache.Add("https://example.com", []byte("testdata"))
 
 	_, ok := cache.Get("https://example.com")
@@ -51,8 +66,13 @@ func TestReapLoop(t *testing.T) {
 		return
 	}
 
-	time.Sleep(waitTime)
+	clock.Advance(waitTime)
+	cache.reap()
 
+	if stats := cache.Stats(); stats.Entries != 0 {
+		t.Errorf("expected to reap the entry")
+		return
+	}
 	_, ok = cache.Get("https://example.com")
 	if ok {
 		t.Errorf("expected to not find key")
@@ -60,3 +80,153 @@ func TestReapLoop(t *testing.T) {
 	}
 }
 
+func TestRealLoopReapsUntilClosed(t *testing.T) {
+	const interval = 10 * time.Millisecond
+	cache := NewCache(interval)
+	cache.Add("https://example.com", []byte("testdata"))
+
+	deadline := time.Now().Add(time.Second)
+	for cache.Stats().Entries != 0 && time.Now().Before(deadline) {
+		time.Sleep(interval)
+	}
+	if stats := cache.Stats(); stats.Entries != 0 {
+		t.Errorf("expected the loop to reap the entry, got %+v", stats)
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		cache.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Errorf("expected Close to stop the loop")
+		return
+	}
+	cache.Add("https://example.com", []byte("testdata"))
+	time.Sleep(5 * interval)
+	if stats := cache.Stats(); stats.Entries != 1 {
+		t.Errorf("expected no reaping after Close, got %+v", stats)
+	}
+}
+
+func TestAddWithTTL(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	cache := NewCacheWithClock(time.Minute, Limits{}, clock)
+	defer cache.Close()
+	cache.AddWithTTL("short", []byte("testdata"), time.Second)
+	cache.AddWithTTL("long", []byte("testdata"), time.Hour)
+
+	clock.Advance(2 * time.Minute)
+	cache.reap()
+
+	if _, ok := cache.Get("short"); ok {
+		t.Errorf("expected short lived entry to expire")
+	}
+	if _, ok := cache.Get("long"); !ok {
+		t.Errorf("expected long lived entry to outlive the interval")
+	}
+}
+
+func TestCloseIsIdempotent(t *testing.T) {
+	cache := NewCache(time.Millisecond)
+	cache.Close()
+	cache.Close()
+}
+
+func TestStaleEntryRevalidation(t *testing.T) {
+	const baseTime = 5 * time.Minute
+	const waitTime = baseTime + 5*time.Minute
+	clock := &fakeClock{now: time.Now()}
+	cache := NewCacheWithClock(baseTime, Limits{}, clock)
+	defer cache.Close()
+	validators := Validators{ETag: `"abc"`}
+	cache.AddWithValidators("https://example.com", []byte("testdata"), validators)
+
+	clock.Advance(waitTime)
+	cache.reap()
+
+	if _, ok := cache.Get("https://example.com"); ok {
+		t.Errorf("expected stale entry to be missed by Get")
+		return
+	}
+	val, got, ok := cache.GetStale("https://example.com")
+	if !ok {
+		t.Errorf("expected to find stale entry")
+		return
+	}
+	if string(val) != "testdata" || got != validators {
+		t.Errorf("expected stale entry to keep its value and validators")
+		return
+	}
+
+	cache.Refresh("https://example.com")
+	if _, ok := cache.Get("https://example.com"); !ok {
+		t.Errorf("expected refreshed entry to be fresh")
+	}
+}
+
+func TestLRUEvictionOrder(t *testing.T) {
+	cache := NewBoundedCache(time.Minute, Limits{MaxEntries: 2})
+	defer cache.Close()
+	cache.Add("a", []byte("1"))
+	cache.Add("b", []byte("2"))
+	// Touching "a" makes "b" the least recently used entry.
+	cache.Get("a")
+	cache.Add("c", []byte("3"))
+
+	cases := []struct {
+		key      string
+		expected bool
+	}{
+		{key: "a", expected: true},
+		{key: "b", expected: false},
+		{key: "c", expected: true},
+	}
+	for _, c := range cases {
+		if _, ok := cache.Get(c.key); ok != c.expected {
+			t.Errorf("expected presence of %v to be %v", c.key, c.expected)
+		}
+	}
+	if stats := cache.Stats(); stats.Evictions != 1 || stats.Entries != 2 {
+		t.Errorf("expected 1 eviction and 2 entries, got %+v", stats)
+	}
+}
+
+func TestByteBudget(t *testing.T) {
+	cache := NewBoundedCache(time.Minute, Limits{MaxBytes: 10})
+	defer cache.Close()
+	cache.Add("a", []byte("12345"))
+	cache.Add("b", []byte("12345"))
+	cache.Add("c", []byte("123"))
+
+	if _, ok := cache.Get("a"); ok {
+		t.Errorf("expected oldest entry to be evicted")
+	}
+	if stats := cache.Stats(); stats.Bytes != 8 {
+		t.Errorf("expected 8 bytes stored, got %v", stats.Bytes)
+	}
+}
+
+func TestStatsAndClear(t *testing.T) {
+	cache := NewCache(time.Minute)
+	defer cache.Close()
+	cache.Add("https://example.com/area/1", []byte("testdata"))
+	cache.Add("https://example.com/area/2", []byte("testdata"))
+	cache.Add("https://example.com/pokemon/1", []byte("testdata"))
+	cache.Get("https://example.com/area/1")
+	cache.Get("https://example.com/missing")
+
+	stats := cache.Stats()
+	if stats.Hits != 1 || stats.Misses != 1 || stats.Bytes != 24 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	if removed := cache.Clear("https://example.com/area/"); removed != 2 {
+		t.Errorf("expected 2 removed entries, got %v", removed)
+	}
+	if stats := cache.Stats(); stats.Entries != 1 || stats.Bytes != 8 {
+		t.Errorf("unexpected stats after clear %+v", stats)
+	}
+}
@@ -1,57 +1,265 @@
 package pokecache
 
 import (
+	"container/list"
+	"strings"
 	"sync"
 	"time"
 )
 
+// Entries carrying validators outlive the interval by this factor so they can
+// be revalidated with a conditional request instead of being refetched.
+const staleFactor = 60
+
+type Validators struct {
+	ETag         string
+	LastModified string
+}
+
+func (v Validators) empty() bool {
+	return v.ETag == "" && v.LastModified == ""
+}
+
+// Limits bounds the cache size, a zero field means no limit.
+type Limits struct {
+	MaxEntries int
+	MaxBytes   int
+}
+
+// DefaultLimits keep a long session under a few dozen megabytes, far more
+// than the pages, areas and pokemon a player visits in one sitting.
+var DefaultLimits = Limits{MaxEntries: 500, MaxBytes: 32 << 20}
+
+type Stats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	Entries   int
+	Bytes     int
+}
+
+type Clock interface {
+	Now() time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
 type cacheEntry struct {
-	createdAt time.Time
-	val       []byte
+	key        string
+	createdAt  time.Time
+	ttl        time.Duration
+	val        []byte
+	value      any
+	size       int
+	validators Validators
+}
+
+func (e *cacheEntry) age(now time.Time) time.Duration {
+	return now.Sub(e.createdAt)
 }
 
 type Cache struct {
-	entries map[string]cacheEntry
-	mu      *sync.RWMutex
+	entries  map[string]*list.Element
+	order    *list.List
+	mu       *sync.RWMutex
+	stats    *Stats
+	interval time.Duration
+	limits   Limits
+	clock    Clock
+	done     chan struct{}
+	stopped  chan struct{}
+	stopOnce *sync.Once
 }
 
 func (c Cache) Add(key string, val []byte) {
+	c.add(&cacheEntry{key: key, ttl: c.interval, val: val, size: len(val)})
+}
+
+func (c Cache) AddWithTTL(key string, val []byte, ttl time.Duration) {
+	c.add(&cacheEntry{key: key, ttl: ttl, val: val, size: len(val)})
+}
+
+func (c Cache) AddWithValidators(key string, val []byte, validators Validators) {
+	c.add(&cacheEntry{key: key, ttl: c.interval, val: val, size: len(val), validators: validators})
+}
+
+func (c Cache) add(entry *cacheEntry) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	c.entries[key] = cacheEntry{
-		createdAt: time.Now(),
-		val:       val,
+	if element, ok := c.entries[entry.key]; ok {
+		c.remove(element)
 	}
+	entry.createdAt = c.clock.Now()
+	c.entries[entry.key] = c.order.PushFront(entry)
+	c.stats.Entries++
+	c.stats.Bytes += entry.size
+	c.evict()
 }
 
 func (c Cache) Get(key string) ([]byte, bool) {
+	entry, ok := c.lookup(key)
+	if !ok {
+		return nil, false
+	}
+	return entry.val, true
+}
+
+func (c Cache) lookup(key string) (*cacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[key]
+	if !ok {
+		c.stats.Misses++
+		return nil, false
+	}
+	entry := element.Value.(*cacheEntry)
+	if entry.age(c.clock.Now()) > entry.ttl {
+		c.stats.Misses++
+		return nil, false
+	}
+	c.stats.Hits++
+	c.order.MoveToFront(element)
+	return entry, true
+}
+
+func (c Cache) GetStale(key string) ([]byte, Validators, bool) {
+	entry, ok := c.stale(key)
+	if !ok {
+		return nil, Validators{}, false
+	}
+	return entry.val, entry.validators, true
+}
+
+// stale finds an entry, expired or not, that can be revalidated.
+func (c Cache) stale(key string) (*cacheEntry, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	element, ok := c.entries[key]
+	if !ok || element.Value.(*cacheEntry).validators.empty() {
+		return nil, false
+	}
+	return element.Value.(*cacheEntry), true
+}
+
+func (c Cache) Refresh(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[key]
+	if !ok {
+		return
+	}
+	element.Value.(*cacheEntry).createdAt = c.clock.Now()
+	c.order.MoveToFront(element)
+}
+
+func (c Cache) Clear(prefix string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	removed := 0
+	for key, element := range c.entries {
+		if strings.HasPrefix(key, prefix) {
+			c.remove(element)
+			removed++
+		}
+	}
+	return removed
+}
+
+func (c Cache) Stats() Stats {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	cacheEntry, ok := c.entries[key]
-	return cacheEntry.val, ok
+	return *c.stats
+}
+
+func (c Cache) remove(element *list.Element) {
+	entry := element.Value.(*cacheEntry)
+	c.order.Remove(element)
+	delete(c.entries, entry.key)
+	c.stats.Entries--
+	c.stats.Bytes -= entry.size
+}
+
+func (c Cache) evict() {
+	for c.overBudget() {
+		oldest := c.order.Back()
+		if oldest == nil {
+			return
+		}
+		c.remove(oldest)
+		c.stats.Evictions++
+	}
+}
+
+func (c Cache) overBudget() bool {
+	if c.limits.MaxEntries > 0 && c.stats.Entries > c.limits.MaxEntries {
+		return true
+	}
+	return c.limits.MaxBytes > 0 && c.stats.Bytes > c.limits.MaxBytes
+}
+
+// Close stops the reaper and waits for it to return.
+func (c Cache) Close() {
+	c.stopOnce.Do(func() {
+		close(c.done)
+	})
+	<-c.stopped
+}
+
+func (c Cache) reap() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.clock.Now()
+	for _, element := range c.entries {
+		entry := element.Value.(*cacheEntry)
+		maxAge := entry.ttl
+		if !entry.validators.empty() {
+			maxAge = staleFactor * entry.ttl
+		}
+		if entry.age(now) > maxAge {
+			c.remove(element)
+		}
+	}
 }
 
 func (c Cache) realLoop(interval time.Duration) {
+	defer close(c.stopped)
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
-	for true {
+	for {
 		select {
 		case <-ticker.C:
-			c.mu.Lock()
-			for key, entry := range c.entries {
-				if time.Since(entry.createdAt) > interval {
-					delete(c.entries, key)
-				}
-			}
-			c.mu.Unlock()
+			c.reap()
+		case <-c.done:
+			return
 		}
 	}
 }
+
 func NewCache(interval time.Duration) Cache {
+	return NewBoundedCache(interval, Limits{})
+}
+
+func NewBoundedCache(interval time.Duration, limits Limits) Cache {
+	return NewCacheWithClock(interval, limits, realClock{})
+}
+
+func NewCacheWithClock(interval time.Duration, limits Limits, clock Clock) Cache {
 	cache := Cache{
-		entries: map[string]cacheEntry{},
-		mu:      &sync.RWMutex{},
+		entries:  map[string]*list.Element{},
+		order:    list.New(),
+		mu:       &sync.RWMutex{},
+		stats:    &Stats{},
+		interval: interval,
+		limits:   limits,
+		clock:    clock,
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+		stopOnce: &sync.Once{},
 	}
 	go cache.realLoop(interval)
 	return cache
@@ -0,0 +1,73 @@
+package pokecache
+
+import (
+	"encoding/json"
+)
+
+// TypedCache keeps decoded values in the shared cache so hits skip JSON
+// decoding. Entries are keyed by resource kind and id, and count against the
+// cache limits with the size of the body they were decoded from. The body
+// itself isn't kept, only its validators so an expired value can be
+// revalidated.
+type TypedCache[T any] struct {
+	cache Cache
+	kind  string
+}
+
+func NewTypedCache[T any](cache Cache, kind string) TypedCache[T] {
+	return TypedCache[T]{
+		cache: cache,
+		kind:  kind,
+	}
+}
+
+func (t TypedCache[T]) Kind() string {
+	return t.kind
+}
+
+func (t TypedCache[T]) Key(id string) string {
+	return t.kind + "/" + id
+}
+
+func (t TypedCache[T]) Get(id string) (T, bool) {
+	entry, ok := t.cache.lookup(t.Key(id))
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	val, ok := entry.value.(T)
+	return val, ok
+}
+
+func (t TypedCache[T]) Add(id string, val T, size int) {
+	t.cache.add(&cacheEntry{key: t.Key(id), ttl: t.cache.interval, value: val, size: size})
+}
+
+func (t TypedCache[T]) AddWithValidators(id string, val T, size int, validators Validators) {
+	t.cache.add(&cacheEntry{key: t.Key(id), ttl: t.cache.interval, value: val, size: size, validators: validators})
+}
+
+// GetStale returns a value past its TTL along with the validators to
+// revalidate it with.
+func (t TypedCache[T]) GetStale(id string) (T, Validators, bool) {
+	var zero T
+	entry, ok := t.cache.stale(t.Key(id))
+	if !ok {
+		return zero, Validators{}, false
+	}
+	val, ok := entry.value.(T)
+	return val, entry.validators, ok
+}
+
+func (t TypedCache[T]) Refresh(id string) {
+	t.cache.Refresh(t.Key(id))
+}
+
+func (t TypedCache[T]) Decode(id string, body []byte) (T, error) {
+	var val T
+	if err := json.Unmarshal(body, &val); err != nil {
+		return val, err
+	}
+	t.Add(id, val, len(body))
+	return val, nil
+}
@@ -0,0 +1,99 @@
+package pokecache
+
+import (
+	"testing"
+	"time"
+)
+
+type testResource struct {
+	Name string `json:"name"`
+	ID   int    `json:"id"`
+}
+
+func TestTypedCacheDecode(t *testing.T) {
+	cache := NewCache(time.Minute)
+	defer cache.Close()
+	typed := NewTypedCache[testResource](cache, "pokemon")
+
+	if _, ok := typed.Get("pikachu"); ok {
+		t.Errorf("expected not to find key")
+		return
+	}
+	body := []byte(`{"name":"pikachu","id":25}`)
+	val, err := typed.Decode("pikachu", body)
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+		return
+	}
+	if val.ID != 25 {
+		t.Errorf("expected decoded id 25, got %v", val.ID)
+	}
+
+	cached, ok := typed.Get("pikachu")
+	if !ok || cached != val {
+		t.Errorf("expected to find decoded value")
+	}
+	if stats := cache.Stats(); stats.Bytes != len(body) {
+		t.Errorf("expected %v bytes stored, got %v", len(body), stats.Bytes)
+	}
+}
+
+func TestTypedCacheKindsDoNotCollide(t *testing.T) {
+	cache := NewCache(time.Minute)
+	defer cache.Close()
+	pokemons := NewTypedCache[testResource](cache, "pokemon")
+	areas := NewTypedCache[string](cache, "location-area")
+
+	pokemons.Add("1", testResource{Name: "bulbasaur", ID: 1}, 1)
+	if _, ok := areas.Get("1"); ok {
+		t.Errorf("expected kinds to use separate keys")
+	}
+}
+
+func TestTypedCacheRevalidation(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	cache := NewCacheWithClock(time.Minute, Limits{}, clock)
+	defer cache.Close()
+	typed := NewTypedCache[testResource](cache, "pokemon")
+	validators := Validators{ETag: `"v1"`}
+	typed.AddWithValidators("25", testResource{Name: "pikachu", ID: 25}, 26, validators)
+
+	clock.Advance(2 * time.Minute)
+	if _, ok := typed.Get("25"); ok {
+		t.Errorf("expected the expired value to be missed by Get")
+	}
+	val, got, ok := typed.GetStale("25")
+	if !ok || val.Name != "pikachu" || got != validators {
+		t.Errorf("expected the stale value and its validators, got %v, %v", val, got)
+	}
+	typed.Refresh("25")
+	if _, ok := typed.Get("25"); !ok {
+		t.Errorf("expected the refreshed value to be fresh")
+	}
+	if stats := cache.Stats(); stats.Entries != 1 || stats.Bytes != 26 {
+		t.Errorf("expected a single entry of 26 bytes, got %+v", stats)
+	}
+}
+
+func TestAliasesResolve(t *testing.T) {
+	aliases := NewAliases()
+	aliases.Learn("location-area", "canto-route-1", 295)
+
+	cases := []struct {
+		kind     string
+		name     string
+		expected int
+		ok       bool
+	}{
+		{kind: "location-area", name: "canto-route-1", expected: 295, ok: true},
+		{kind: "location-area", name: "Canto-Route-1", expected: 295, ok: true},
+		{kind: "location-area", name: "295", expected: 295, ok: true},
+		{kind: "pokemon", name: "canto-route-1", ok: false},
+	}
+	for _, c := range cases {
+		id, ok := aliases.Resolve(c.kind, c.name)
+		if id != c.expected || ok != c.ok {
+			t.Errorf("expected (%v, %v) for %v/%v, got (%v, %v)", c.expected, c.ok, c.kind, c.name, id, ok)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type Format string
+
+const (
+	Text  Format = "text"
+	JSON  Format = "json"
+	YAML  Format = "yaml"
+	Table Format = "table"
+)
+
+func ParseFormat(value string) (Format, error) {
+	switch format := Format(strings.ToLower(value)); format {
+	case Text, JSON, YAML, Table:
+		return format, nil
+	default:
+		return "", fmt.Errorf("Unknown output format %q, expected json, yaml, table or text", value)
+	}
+}
+
+// Texter is implemented by results with a human readable form.
+type Texter interface {
+	Text() string
+}
+
+// Tabler is implemented by results that can be shown as rows and columns.
+// The style colors and decorates cells for the current terminal.
+type Tabler interface {
+	Table(style Style) ([]string, [][]string)
+}
+
+type Renderer struct {
+	Writer io.Writer
+	Format Format
+	Style  Style
+}
+
+func (r *Renderer) Render(v any) error {
+	switch r.Format {
+	case JSON:
+		encoder := json.NewEncoder(r.Writer)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	case YAML:
+		return EncodeYAML(r.Writer, v)
+	case Table:
+		if tabler, ok := v.(Tabler); ok {
+			headers, rows := tabler.Table(r.Style)
+			return writeTable(r.Writer, r.Style, headers, rows)
+		}
+	}
+	if texter, ok := v.(Texter); ok {
+		_, err := fmt.Fprintln(r.Writer, texter.Text())
+		return err
+	}
+	_, err := fmt.Fprintln(r.Writer, v)
+	return err
+}
@@ -0,0 +1,118 @@
+package render
+
+import (
+	"bytes"
+	"testing"
+)
+
+type testResult struct {
+	Name  string   `json:"name"`
+	ID    int      `json:"id"`
+	Types []string `json:"types"`
+	Stats []struct {
+		Name  string `json:"name"`
+		Value int    `json:"value"`
+	} `json:"stats"`
+	Empty []string `json:"empty"`
+}
+
+func (r testResult) Text() string {
+	return "Name: " + r.Name
+}
+
+func (r testResult) Table(style Style) ([]string, [][]string) {
+	return []string{"name", "types"}, [][]string{{r.Name, style.Types(r.Types)}}
+}
+
+func newTestResult() testResult {
+	result := testResult{Name: "pikachu", ID: 25, Types: []string{"electric"}, Empty: []string{}}
+	result.Stats = append(result.Stats, struct {
+		Name  string `json:"name"`
+		Value int    `json:"value"`
+	}{Name: "hp", Value: 35})
+	return result
+}
+
+func TestRender(t *testing.T) {
+	cases := []struct {
+		format   Format
+		expected string
+	}{
+		{format: Text, expected: "Name: pikachu\n"},
+		{format: JSON, expected: "{\n  \"name\": \"pikachu\",\n  \"id\": 25,\n  \"types\": [\n    \"electric\"\n  ],\n  \"stats\": [\n    {\n      \"name\": \"hp\",\n      \"value\": 35\n    }\n  ],\n  \"empty\": []\n}\n"},
+		{format: YAML, expected: "name: pikachu\nid: 25\ntypes:\n  - electric\nstats:\n  - name: hp\n    value: 35\nempty: []\n"},
+		{format: Table, expected: "NAME     TYPES\npikachu  electric\n"},
+	}
+
+	for _, c := range cases {
+		t.Run(string(c.format), func(t *testing.T) {
+			out := bytes.Buffer{}
+			renderer := Renderer{Writer: &out, Format: c.format}
+			if err := renderer.Render(newTestResult()); err != nil {
+				t.Errorf("expected no error, got %v", err)
+				return
+			}
+			if out.String() != c.expected {
+				t.Errorf("expected %q, got %q", c.expected, out.String())
+			}
+		})
+	}
+}
+
+func TestYAMLQuotesAmbiguousStrings(t *testing.T) {
+	out := bytes.Buffer{}
+	if err := EncodeYAML(&out, []string{"yes", "25", "- dash", "plain"}); err != nil {
+		t.Errorf("expected no error, got %v", err)
+		return
+	}
+	expected := "- \"yes\"\n- \"25\"\n- \"- dash\"\n- plain\n"
+	if out.String() != expected {
+		t.Errorf("expected %q, got %q", expected, out.String())
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Errorf("expected an error for an unknown format")
+	}
+	if format, err := ParseFormat("JSON"); err != nil || format != JSON {
+		t.Errorf("expected json, got %v, %v", format, err)
+	}
+}
+
+func TestTableColorAndTruncation(t *testing.T) {
+	out := bytes.Buffer{}
+	renderer := Renderer{Writer: &out, Format: Table, Style: Style{Color: true}}
+	if err := renderer.Render(newTestResult()); err != nil {
+		t.Errorf("expected no error, got %v", err)
+		return
+	}
+	lines := bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n"))
+	if len(lines) != 2 || visibleWidth(string(lines[1])) != len("pikachu   electric ") {
+		t.Errorf("expected badges not to break alignment, got %q", out.String())
+	}
+
+	out.Reset()
+	renderer = Renderer{Writer: &out, Format: Table, Style: Style{Width: 12}}
+	renderer.Render(newTestResult())
+	expected := "NAME   TYPES\npika…  elec…\n"
+	if out.String() != expected {
+		t.Errorf("expected %q, got %q", expected, out.String())
+	}
+}
+
+func TestBar(t *testing.T) {
+	cases := []struct {
+		value    int
+		expected string
+	}{
+		{value: 0, expected: "......"},
+		{value: 128, expected: "###..."},
+		{value: 300, expected: "######"},
+	}
+	for _, c := range cases {
+		if got := (Style{}).Bar(c.value, 255, 6); got != c.expected {
+			t.Errorf("expected %q for %v, got %q", c.expected, c.value, got)
+		}
+	}
+}
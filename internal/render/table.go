@@ -0,0 +1,161 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+var ansi = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+var typeColors = map[string]string{
+	"normal":   "38;5;0;48;5;250",
+	"fire":     "38;5;15;48;5;160",
+	"water":    "38;5;15;48;5;27",
+	"electric": "38;5;0;48;5;220",
+	"grass":    "38;5;0;48;5;70",
+	"ice":      "38;5;0;48;5;117",
+	"fighting": "38;5;15;48;5;124",
+	"poison":   "38;5;15;48;5;91",
+	"ground":   "38;5;0;48;5;179",
+	"flying":   "38;5;0;48;5;147",
+	"psychic":  "38;5;15;48;5;205",
+	"bug":      "38;5;0;48;5;106",
+	"rock":     "38;5;15;48;5;137",
+	"ghost":    "38;5;15;48;5;60",
+	"dragon":   "38;5;15;48;5;57",
+	"dark":     "38;5;15;48;5;236",
+	"steel":    "38;5;0;48;5;248",
+	"fairy":    "38;5;0;48;5;218",
+}
+
+// Style decorates table cells. Without Color every helper returns plain
+// text, and a zero Width means the table is never truncated.
+type Style struct {
+	Color bool
+	Width int
+}
+
+func (s Style) paint(code, text string) string {
+	if !s.Color || code == "" {
+		return text
+	}
+	return "\x1b[" + code + "m" + text + "\x1b[0m"
+}
+
+func (s Style) Header(text string) string {
+	return s.paint("1", strings.ToUpper(text))
+}
+
+// Type renders a pokemon type as a badge in the type's usual color.
+func (s Style) Type(name string) string {
+	if !s.Color {
+		return name
+	}
+	return s.paint(typeColors[name], " "+name+" ")
+}
+
+func (s Style) Types(names []string) string {
+	badges := []string{}
+	for _, name := range names {
+		badges = append(badges, s.Type(name))
+	}
+	return strings.Join(badges, " ")
+}
+
+// Bar draws value against maxValue on width cells, red for low values,
+// yellow for average ones and green for high ones.
+func (s Style) Bar(value, maxValue, width int) string {
+	filled := 0
+	if maxValue > 0 {
+		filled = min(width, max(0, value*width/maxValue))
+	}
+	code := "32"
+	switch {
+	case filled*3 < width:
+		code = "31"
+	case filled*3 < width*2:
+		code = "33"
+	}
+	if !s.Color {
+		return strings.Repeat("#", filled) + strings.Repeat(".", width-filled)
+	}
+	return s.paint(code, strings.Repeat("█", filled)) + strings.Repeat("░", width-filled)
+}
+
+func visibleWidth(text string) int {
+	return utf8.RuneCountInString(ansi.ReplaceAllString(text, ""))
+}
+
+func truncate(text string, width int) string {
+	if visibleWidth(text) <= width {
+		return text
+	}
+	// Escape codes can't be cut safely, the cell loses its color instead.
+	runes := []rune(ansi.ReplaceAllString(text, ""))
+	if width <= 1 {
+		return string(runes[:max(width, 0)])
+	}
+	return string(runes[:width-1]) + "…"
+}
+
+const columnGap = 2
+
+func writeTable(w io.Writer, style Style, headers []string, rows [][]string) error {
+	all := [][]string{make([]string, len(headers))}
+	for i, header := range headers {
+		all[0][i] = style.Header(header)
+	}
+	all = append(all, rows...)
+
+	widths := make([]int, len(headers))
+	for _, row := range all {
+		for i, cell := range row {
+			widths[i] = max(widths[i], visibleWidth(cell))
+		}
+	}
+	shrink(widths, style.Width)
+
+	for _, row := range all {
+		line := strings.Builder{}
+		for i, cell := range row {
+			cell = truncate(cell, widths[i])
+			line.WriteString(cell)
+			if i < len(row)-1 {
+				line.WriteString(strings.Repeat(" ", widths[i]-visibleWidth(cell)+columnGap))
+			}
+		}
+		if _, err := fmt.Fprintln(w, line.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// shrink narrows the widest columns one cell at a time until the table fits.
+func shrink(widths []int, limit int) {
+	if limit <= 0 {
+		return
+	}
+	total := func() int {
+		sum := columnGap * (len(widths) - 1)
+		for _, width := range widths {
+			sum += width
+		}
+		return sum
+	}
+	for total() > limit {
+		widest := 0
+		for i, width := range widths {
+			if width > widths[widest] {
+				widest = i
+			}
+		}
+		if widths[widest] <= 3 {
+			return
+		}
+		widths[widest]--
+	}
+}
@@ -0,0 +1,127 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// EncodeYAML writes v as YAML. The value goes through its JSON encoding
+// first, so json tags name the fields and their order is kept.
+func EncodeYAML(w io.Writer, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	root, err := decodeNode(decoder)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, strings.Join(root.lines(), "\n")+"\n")
+	return err
+}
+
+type node struct {
+	keys     []string
+	children []node
+	object   bool
+	array    bool
+	scalar   string
+}
+
+func decodeNode(decoder *json.Decoder) (node, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return node{}, err
+	}
+	switch value := token.(type) {
+	case json.Delim:
+		n := node{object: value == '{', array: value == '['}
+		for decoder.More() {
+			if n.object {
+				keyToken, err := decoder.Token()
+				if err != nil {
+					return node{}, err
+				}
+				n.keys = append(n.keys, keyToken.(string))
+			}
+			child, err := decodeNode(decoder)
+			if err != nil {
+				return node{}, err
+			}
+			n.children = append(n.children, child)
+		}
+		// Consume the closing delimiter.
+		_, err := decoder.Token()
+		return n, err
+	case string:
+		return node{scalar: quote(value)}, nil
+	case json.Number:
+		return node{scalar: value.String()}, nil
+	case bool:
+		return node{scalar: strconv.FormatBool(value)}, nil
+	default:
+		return node{scalar: "null"}, nil
+	}
+}
+
+// inline reports whether the node fits on the line of its key or dash.
+func (n node) inline() bool {
+	return (!n.object && !n.array) || len(n.children) == 0
+}
+
+func (n node) lines() []string {
+	switch {
+	case n.object && len(n.children) == 0:
+		return []string{"{}"}
+	case n.array && len(n.children) == 0:
+		return []string{"[]"}
+	case n.object:
+		lines := []string{}
+		for i, child := range n.children {
+			key := quote(n.keys[i])
+			if child.inline() {
+				lines = append(lines, key+": "+child.lines()[0])
+				continue
+			}
+			lines = append(lines, key+":")
+			for _, line := range child.lines() {
+				lines = append(lines, "  "+line)
+			}
+		}
+		return lines
+	case n.array:
+		lines := []string{}
+		for _, child := range n.children {
+			for i, line := range child.lines() {
+				if i == 0 {
+					lines = append(lines, "- "+line)
+				} else {
+					lines = append(lines, "  "+line)
+				}
+			}
+		}
+		return lines
+	default:
+		return []string{n.scalar}
+	}
+}
+
+func quote(value string) string {
+	if value == "" || strings.TrimSpace(value) != value || strings.ContainsAny(value, ":#\n\t\"'\\") ||
+		strings.ContainsAny(value[:1], "-?[]{},&*!|>%@`") {
+		return strconv.Quote(value)
+	}
+	switch strings.ToLower(value) {
+	case "true", "false", "yes", "no", "on", "off", "null", "~":
+		return strconv.Quote(value)
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return strconv.Quote(value)
+	}
+	return value
+}
@@ -0,0 +1,188 @@
+package search
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/c00rni/pokedex/internal/fuzzy"
+)
+
+type Entry struct {
+	Kind   string   `json:"kind"`
+	Name   string   `json:"name"`
+	Labels []string `json:"labels,omitempty"`
+}
+
+type Result struct {
+	Entry
+	Score int
+}
+
+// Index is a name index over PokeAPI resources, persisted as JSON so it only
+// has to be built from the list endpoints once. The kinds built from a full
+// list are marked complete, the others only hold the resources added one by
+// one.
+type Index struct {
+	mu       *sync.RWMutex
+	entries  map[string]*Entry
+	complete map[string]bool
+	path     string
+}
+
+// file is the saved index. Files from before the complete kinds were tracked
+// are a bare list of entries.
+type file struct {
+	Complete []string `json:"complete"`
+	Entries  []*Entry `json:"entries"`
+}
+
+func NewIndex(path string) Index {
+	return Index{
+		mu:       &sync.RWMutex{},
+		entries:  map[string]*Entry{},
+		complete: map[string]bool{},
+		path:     path,
+	}
+}
+
+func Load(path string) (Index, error) {
+	index := NewIndex(path)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return index, nil
+	}
+	if err != nil {
+		return index, err
+	}
+	saved := file{}
+	if err := json.Unmarshal(data, &saved); err != nil {
+		if err := json.Unmarshal(data, &saved.Entries); err != nil {
+			return index, err
+		}
+	}
+	for _, entry := range saved.Entries {
+		index.entries[entry.Kind+"/"+entry.Name] = entry
+	}
+	for _, kind := range saved.Complete {
+		index.complete[kind] = true
+	}
+	return index, nil
+}
+
+func (i Index) Save() error {
+	if i.path == "" {
+		return nil
+	}
+	i.mu.RLock()
+	saved := file{Complete: []string{}, Entries: make([]*Entry, 0, len(i.entries))}
+	for _, entry := range i.entries {
+		saved.Entries = append(saved.Entries, entry)
+	}
+	for kind := range i.complete {
+		saved.Complete = append(saved.Complete, kind)
+	}
+	i.mu.RUnlock()
+	sort.Strings(saved.Complete)
+	sort.Slice(saved.Entries, func(a, b int) bool {
+		return saved.Entries[a].Kind+"/"+saved.Entries[a].Name < saved.Entries[b].Kind+"/"+saved.Entries[b].Name
+	})
+
+	data, err := json.Marshal(saved)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(i.path), 0o755); err != nil {
+		return err
+	}
+	tmp := i.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, i.path)
+}
+
+func (i Index) Add(kind, name string, labels ...string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	key := kind + "/" + name
+	entry, ok := i.entries[key]
+	if !ok {
+		entry = &Entry{Kind: kind, Name: name}
+		i.entries[key] = entry
+	}
+	for _, label := range labels {
+		if label != "" && label != name && !contains(entry.Labels, label) {
+			entry.Labels = append(entry.Labels, label)
+		}
+	}
+}
+
+// MarkComplete records that every resource of the kind has been added.
+func (i Index) MarkComplete(kind string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.complete[kind] = true
+}
+
+func (i Index) Complete(kind string) bool {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.complete[kind]
+}
+
+func (i Index) Names(kind string) []string {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	names := []string{}
+	for _, entry := range i.entries {
+		if entry.Kind == kind {
+			names = append(names, entry.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Search scores every entry of the kind, or of all kinds when kind is empty,
+// against its name and localized labels and returns the best matches first.
+func (i Index) Search(query, kind string, limit int) []Result {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	results := []Result{}
+	for _, entry := range i.entries {
+		if kind != "" && entry.Kind != kind {
+			continue
+		}
+		score := fuzzy.Score(query, entry.Name)
+		for _, label := range entry.Labels {
+			score = max(score, fuzzy.Score(query, label))
+		}
+		if score > 0 {
+			results = append(results, Result{Entry: *entry, Score: score})
+		}
+	}
+	sort.Slice(results, func(a, b int) bool {
+		if results[a].Score != results[b].Score {
+			return results[a].Score > results[b].Score
+		}
+		return results[a].Name < results[b].Name
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package search
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSearchRanking(t *testing.T) {
+	index := NewIndex("")
+	index.Add("pokemon", "pikachu")
+	index.Add("pokemon", "pichu")
+	index.Add("pokemon", "raichu")
+	index.Add("location-area", "pallet-town-area", "Bourg Palette")
+
+	cases := []struct {
+		query    string
+		kind     string
+		expected string
+	}{
+		{query: "pika", kind: "", expected: "pikachu"},
+		{query: "raichuu", kind: "pokemon", expected: "raichu"},
+		{query: "palette", kind: "", expected: "pallet-town-area"},
+	}
+	for _, c := range cases {
+		results := index.Search(c.query, c.kind, 3)
+		if len(results) == 0 || results[0].Name != c.expected {
+			t.Errorf("expected %v first for %q, got %+v", c.expected, c.query, results)
+		}
+	}
+	if results := index.Search("pika", "location-area", 3); len(results) != 0 {
+		t.Errorf("expected kind filter to drop pokemon, got %+v", results)
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "search.json")
+	index := NewIndex(path)
+	index.Add("move", "thunderbolt", "Tonnerre")
+	index.MarkComplete("move")
+	index.Add("pokemon", "pikachu")
+	if err := index.Save(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+		return
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+		return
+	}
+	if !loaded.Complete("move") || loaded.Complete("pokemon") {
+		t.Errorf("expected only move to be complete after loading")
+	}
+	if len(loaded.Search("tonnerre", "move", 1)) != 1 {
+		t.Errorf("expected saved entries to be searchable after loading")
+	}
+}
+
+func TestLoadBareEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "search.json")
+	if err := os.WriteFile(path, []byte(`[{"kind":"pokemon","name":"pikachu"}]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+		return
+	}
+	if loaded.Complete("pokemon") || len(loaded.Names("pokemon")) != 1 {
+		t.Errorf("expected the entries without a complete kind, got %v", loaded.Names("pokemon"))
+	}
+}
@@ -0,0 +1,146 @@
+package trainer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const DefaultName = "default"
+
+var (
+	ErrExists   = errors.New("trainer already exists")
+	ErrNotFound = errors.New("no such trainer")
+)
+
+var validName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+type Location struct {
+	Region   string `json:"region,omitempty"`
+	Location string `json:"location,omitempty"`
+	Area     string `json:"area,omitempty"`
+}
+
+// Caught is one pokemon of a trainer, Pokemon holds the API resource or the
+// part of it worth keeping.
+type Caught struct {
+	Key      string          `json:"key"`
+	CaughtAt time.Time       `json:"caught_at"`
+	Pokemon  json.RawMessage `json:"pokemon"`
+}
+
+type Profile struct {
+	Name     string   `json:"name"`
+	Caught   []Caught `json:"caught"`
+	Seen     []int    `json:"seen"`
+	Location Location `json:"location"`
+}
+
+// Store keeps each trainer in its own <name>.json file next to its
+// <name>.journal.jsonl journal, and the active trainer name in a file named
+// active.
+type Store struct {
+	dir string
+}
+
+func NewStore(dir string) Store {
+	return Store{dir: dir}
+}
+
+func ValidateName(name string) error {
+	if !validName.MatchString(name) {
+		return fmt.Errorf("Invalid trainer name %q, use lowercase letters, digits, - and _", name)
+	}
+	return nil
+}
+
+func (s Store) path(name string) string {
+	return filepath.Join(s.dir, name+".json")
+}
+
+func (s Store) JournalPath(name string) string {
+	return filepath.Join(s.dir, name+".journal.jsonl")
+}
+
+func (s Store) Create(name string) (Profile, error) {
+	if err := ValidateName(name); err != nil {
+		return Profile{}, err
+	}
+	if _, err := os.Stat(s.path(name)); err == nil {
+		return Profile{}, fmt.Errorf("%w: %v", ErrExists, name)
+	}
+	profile := Profile{Name: name, Caught: []Caught{}, Seen: []int{}}
+	return profile, s.Save(profile)
+}
+
+func (s Store) Load(name string) (Profile, error) {
+	if err := ValidateName(name); err != nil {
+		return Profile{}, err
+	}
+	data, err := os.ReadFile(s.path(name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return Profile{}, fmt.Errorf("%w: %v", ErrNotFound, name)
+	}
+	if err != nil {
+		return Profile{}, err
+	}
+	profile := Profile{}
+	if err := json.Unmarshal(data, &profile); err != nil {
+		return Profile{}, fmt.Errorf("%v: %w", s.path(name), err)
+	}
+	return profile, nil
+}
+
+// Save writes the profile to a temporary file first, so a crash never
+// leaves a half written trainer behind.
+func (s Store) Save(profile Profile) error {
+	data, err := json.MarshalIndent(profile, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return err
+	}
+	tmp := s.path(profile.Name) + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path(profile.Name))
+}
+
+func (s Store) List() ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(s.dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, path := range paths {
+		names = append(names, strings.TrimSuffix(filepath.Base(path), ".json"))
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Active is the trainer of the last session, DefaultName on the first run.
+func (s Store) Active() string {
+	data, err := os.ReadFile(filepath.Join(s.dir, "active"))
+	name := strings.TrimSpace(string(data))
+	if err != nil || ValidateName(name) != nil {
+		return DefaultName
+	}
+	return name
+}
+
+func (s Store) SetActive(name string) error {
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(s.dir, "active"), []byte(name+"\n"), 0o644)
+}
@@ -0,0 +1,88 @@
+package trainer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCreateLoadSave(t *testing.T) {
+	store := NewStore(t.TempDir())
+	ash, err := store.Create("ash")
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+		return
+	}
+	if _, err := store.Create("ash"); !errors.Is(err, ErrExists) {
+		t.Errorf("expected ErrExists, got %v", err)
+	}
+
+	ash.Caught = append(ash.Caught, Caught{Key: "pikachu", CaughtAt: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), Pokemon: []byte(`{"id":25}`)})
+	ash.Location = Location{Region: "kanto", Area: "viridian-forest-area"}
+	if err := store.Save(ash); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	loaded, err := store.Load("ash")
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+		return
+	}
+	if len(loaded.Caught) != 1 || loaded.Location != ash.Location {
+		t.Errorf("expected the saved profile back, got %+v", loaded)
+		return
+	}
+	pokemon := struct{ ID int }{}
+	if err := json.Unmarshal(loaded.Caught[0].Pokemon, &pokemon); err != nil || pokemon.ID != 25 {
+		t.Errorf("expected the caught pokemon back, got %s", loaded.Caught[0].Pokemon)
+	}
+	if _, err := store.Load("misty"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestNames(t *testing.T) {
+	cases := []struct {
+		name  string
+		valid bool
+	}{
+		{name: "ash", valid: true},
+		{name: "team-rocket_2", valid: true},
+		{name: "", valid: false},
+		{name: "Ash", valid: false},
+		{name: "../ash", valid: false},
+		{name: "-ash", valid: false},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("Test case %v", i), func(t *testing.T) {
+			if err := ValidateName(c.name); (err == nil) != c.valid {
+				t.Errorf("expected %q to be valid: %v, got %v", c.name, c.valid, err)
+			}
+		})
+	}
+}
+
+func TestListAndActive(t *testing.T) {
+	store := NewStore(t.TempDir())
+	if store.Active() != DefaultName {
+		t.Errorf("expected the default trainer first, got %v", store.Active())
+	}
+	for _, name := range []string{"misty", "ash"} {
+		if _, err := store.Create(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.SetActive("misty"); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if store.Active() != "misty" {
+		t.Errorf("expected misty to be active, got %v", store.Active())
+	}
+	names, err := store.List()
+	if err != nil || strings.Join(names, ",") != "ash,misty" {
+		t.Errorf("expected ash and misty, got %v and %v", names, err)
+	}
+}
@@ -2,588 +2,212 @@ package main
 
 import (
 	"bufio"
-	"encoding/json"
+	"context"
 	"errors"
 	"fmt"
-	"github.com/c00rni/pokedex/internal/api"
-	"github.com/c00rni/pokedex/internal/pokecache"
-	"math/rand"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
 	"strings"
-	"time"
-)
 
-type cliCommand struct {
-	name        string
-	description string
-	callback    func(...string) error
-}
+	"github.com/c00rni/pokedex/internal/api"
+	"github.com/c00rni/pokedex/internal/cli"
+	"github.com/c00rni/pokedex/internal/config"
+	"github.com/c00rni/pokedex/internal/pokecache"
+	"github.com/c00rni/pokedex/internal/render"
+)
 
-type response struct {
-	Count    int    `json:"count"`
-	Next     string `json:"next"`
-	Previous string `json:"previous"`
-	Results  []struct {
-		Name string `json:"name"`
-		URL  string `json:"url"`
-	} `json:"results"`
+// outputStyle shows tables on a terminal, in color unless NO_COLOR is set,
+// and keeps plain text lines when the output is piped.
+func outputStyle() (render.Format, render.Style) {
+	info, err := os.Stdout.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return render.Text, render.Style{}
+	}
+	style := render.Style{Color: os.Getenv("NO_COLOR") == "", Width: 80}
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		style.Width = columns
+	}
+	return render.Table, style
 }
 
-type area struct {
-	EncounterMethodRates []struct {
-		EncounterMethod struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"encounter_method"`
-		VersionDetails []struct {
-			Rate    int `json:"rate"`
-			Version struct {
-				Name string `json:"name"`
-				URL  string `json:"url"`
-			} `json:"version"`
-		} `json:"version_details"`
-	} `json:"encounter_method_rates"`
-	GameIndex int `json:"game_index"`
-	ID        int `json:"id"`
-	Location  struct {
-		Name string `json:"name"`
-		URL  string `json:"url"`
-	} `json:"location"`
-	Name  string `json:"name"`
-	Names []struct {
-		Language struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"language"`
-		Name string `json:"name"`
-	} `json:"names"`
-	PokemonEncounters []struct {
-		Pokemon struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"pokemon"`
-		VersionDetails []struct {
-			EncounterDetails []struct {
-				Chance          int   `json:"chance"`
-				ConditionValues []any `json:"condition_values"`
-				MaxLevel        int   `json:"max_level"`
-				Method          struct {
-					Name string `json:"name"`
-					URL  string `json:"url"`
-				} `json:"method"`
-				MinLevel int `json:"min_level"`
-			} `json:"encounter_details"`
-			MaxChance int `json:"max_chance"`
-			Version   struct {
-				Name string `json:"name"`
-				URL  string `json:"url"`
-			} `json:"version"`
-		} `json:"version_details"`
-	} `json:"pokemon_encounters"`
+func indexPath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "pokedex", "search.json")
 }
 
-type pokemon struct {
-	Abilities []struct {
-		Ability struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"ability"`
-		IsHidden bool `json:"is_hidden"`
-		Slot     int  `json:"slot"`
-	} `json:"abilities"`
-	BaseExperience int `json:"base_experience"`
-	Cries          struct {
-		Latest string `json:"latest"`
-		Legacy string `json:"legacy"`
-	} `json:"cries"`
-	Forms []struct {
-		Name string `json:"name"`
-		URL  string `json:"url"`
-	} `json:"forms"`
-	GameIndices []struct {
-		GameIndex int `json:"game_index"`
-		Version   struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"version"`
-	} `json:"game_indices"`
-	Height                 int    `json:"height"`
-	HeldItems              []any  `json:"held_items"`
-	ID                     int    `json:"id"`
-	IsDefault              bool   `json:"is_default"`
-	LocationAreaEncounters string `json:"location_area_encounters"`
-	Moves                  []struct {
-		Move struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"move"`
-		VersionGroupDetails []struct {
-			LevelLearnedAt  int `json:"level_learned_at"`
-			MoveLearnMethod struct {
-				Name string `json:"name"`
-				URL  string `json:"url"`
-			} `json:"move_learn_method"`
-			VersionGroup struct {
-				Name string `json:"name"`
-				URL  string `json:"url"`
-			} `json:"version_group"`
-		} `json:"version_group_details"`
-	} `json:"moves"`
-	Name          string `json:"name"`
-	Order         int    `json:"order"`
-	PastAbilities []any  `json:"past_abilities"`
-	PastTypes     []any  `json:"past_types"`
-	Species       struct {
-		Name string `json:"name"`
-		URL  string `json:"url"`
-	} `json:"species"`
-	Sprites struct {
-		BackDefault      string `json:"back_default"`
-		BackFemale       any    `json:"back_female"`
-		BackShiny        string `json:"back_shiny"`
-		BackShinyFemale  any    `json:"back_shiny_female"`
-		FrontDefault     string `json:"front_default"`
-		FrontFemale      any    `json:"front_female"`
-		FrontShiny       string `json:"front_shiny"`
-		FrontShinyFemale any    `json:"front_shiny_female"`
-		Other            struct {
-			DreamWorld struct {
-				FrontDefault string `json:"front_default"`
-				FrontFemale  any    `json:"front_female"`
-			} `json:"dream_world"`
-			Home struct {
-				FrontDefault     string `json:"front_default"`
-				FrontFemale      any    `json:"front_female"`
-				FrontShiny       string `json:"front_shiny"`
-				FrontShinyFemale any    `json:"front_shiny_female"`
-			} `json:"home"`
-			OfficialArtwork struct {
-				FrontDefault string `json:"front_default"`
-				FrontShiny   string `json:"front_shiny"`
-			} `json:"official-artwork"`
-			Showdown struct {
-				BackDefault      string `json:"back_default"`
-				BackFemale       any    `json:"back_female"`
-				BackShiny        string `json:"back_shiny"`
-				BackShinyFemale  any    `json:"back_shiny_female"`
-				FrontDefault     string `json:"front_default"`
-				FrontFemale      any    `json:"front_female"`
-				FrontShiny       string `json:"front_shiny"`
-				FrontShinyFemale any    `json:"front_shiny_female"`
-			} `json:"showdown"`
-		} `json:"other"`
-		Versions struct {
-			GenerationI struct {
-				RedBlue struct {
-					BackDefault      string `json:"back_default"`
-					BackGray         string `json:"back_gray"`
-					BackTransparent  string `json:"back_transparent"`
-					FrontDefault     string `json:"front_default"`
-					FrontGray        string `json:"front_gray"`
-					FrontTransparent string `json:"front_transparent"`
-				} `json:"red-blue"`
-				Yellow struct {
-					BackDefault      string `json:"back_default"`
-					BackGray         string `json:"back_gray"`
-					BackTransparent  string `json:"back_transparent"`
-					FrontDefault     string `json:"front_default"`
-					FrontGray        string `json:"front_gray"`
-					FrontTransparent string `json:"front_transparent"`
-				} `json:"yellow"`
-			} `json:"generation-i"`
-			GenerationIi struct {
-				Crystal struct {
-					BackDefault           string `json:"back_default"`
-					BackShiny             string `json:"back_shiny"`
-					BackShinyTransparent  string `json:"back_shiny_transparent"`
-					BackTransparent       string `json:"back_transparent"`
-					FrontDefault          string `json:"front_default"`
-					FrontShiny            string `json:"front_shiny"`
-					FrontShinyTransparent string `json:"front_shiny_transparent"`
-					FrontTransparent      string `json:"front_transparent"`
-				} `json:"crystal"`
-				Gold struct {
-					BackDefault      string `json:"back_default"`
-					BackShiny        string `json:"back_shiny"`
-					FrontDefault     string `json:"front_default"`
-					FrontShiny       string `json:"front_shiny"`
-					FrontTransparent string `json:"front_transparent"`
-				} `json:"gold"`
-				Silver struct {
-					BackDefault      string `json:"back_default"`
-					BackShiny        string `json:"back_shiny"`
-					FrontDefault     string `json:"front_default"`
-					FrontShiny       string `json:"front_shiny"`
-					FrontTransparent string `json:"front_transparent"`
-				} `json:"silver"`
-			} `json:"generation-ii"`
-			GenerationIii struct {
-				Emerald struct {
-					FrontDefault string `json:"front_default"`
-					FrontShiny   string `json:"front_shiny"`
-				} `json:"emerald"`
-				FireredLeafgreen struct {
-					BackDefault  string `json:"back_default"`
-					BackShiny    string `json:"back_shiny"`
-					FrontDefault string `json:"front_default"`
-					FrontShiny   string `json:"front_shiny"`
-				} `json:"firered-leafgreen"`
-				RubySapphire struct {
-					BackDefault  string `json:"back_default"`
-					BackShiny    string `json:"back_shiny"`
-					FrontDefault string `json:"front_default"`
-					FrontShiny   string `json:"front_shiny"`
-				} `json:"ruby-sapphire"`
-			} `json:"generation-iii"`
-			GenerationIv struct {
-				DiamondPearl struct {
-					BackDefault      string `json:"back_default"`
-					BackFemale       any    `json:"back_female"`
-					BackShiny        string `json:"back_shiny"`
-					BackShinyFemale  any    `json:"back_shiny_female"`
-					FrontDefault     string `json:"front_default"`
-					FrontFemale      any    `json:"front_female"`
-					FrontShiny       string `json:"front_shiny"`
-					FrontShinyFemale any    `json:"front_shiny_female"`
-				} `json:"diamond-pearl"`
-				HeartgoldSoulsilver struct {
-					BackDefault      string `json:"back_default"`
-					BackFemale       any    `json:"back_female"`
-					BackShiny        string `json:"back_shiny"`
-					BackShinyFemale  any    `json:"back_shiny_female"`
-					FrontDefault     string `json:"front_default"`
-					FrontFemale      any    `json:"front_female"`
-					FrontShiny       string `json:"front_shiny"`
-					FrontShinyFemale any    `json:"front_shiny_female"`
-				} `json:"heartgold-soulsilver"`
-				Platinum struct {
-					BackDefault      string `json:"back_default"`
-					BackFemale       any    `json:"back_female"`
-					BackShiny        string `json:"back_shiny"`
-					BackShinyFemale  any    `json:"back_shiny_female"`
-					FrontDefault     string `json:"front_default"`
-					FrontFemale      any    `json:"front_female"`
-					FrontShiny       string `json:"front_shiny"`
-					FrontShinyFemale any    `json:"front_shiny_female"`
-				} `json:"platinum"`
-			} `json:"generation-iv"`
-			GenerationV struct {
-				BlackWhite struct {
-					Animated struct {
-						BackDefault      string `json:"back_default"`
-						BackFemale       any    `json:"back_female"`
-						BackShiny        string `json:"back_shiny"`
-						BackShinyFemale  any    `json:"back_shiny_female"`
-						FrontDefault     string `json:"front_default"`
-						FrontFemale      any    `json:"front_female"`
-						FrontShiny       string `json:"front_shiny"`
-						FrontShinyFemale any    `json:"front_shiny_female"`
-					} `json:"animated"`
-					BackDefault      string `json:"back_default"`
-					BackFemale       any    `json:"back_female"`
-					BackShiny        string `json:"back_shiny"`
-					BackShinyFemale  any    `json:"back_shiny_female"`
-					FrontDefault     string `json:"front_default"`
-					FrontFemale      any    `json:"front_female"`
-					FrontShiny       string `json:"front_shiny"`
-					FrontShinyFemale any    `json:"front_shiny_female"`
-				} `json:"black-white"`
-			} `json:"generation-v"`
-			GenerationVi struct {
-				OmegarubyAlphasapphire struct {
-					FrontDefault     string `json:"front_default"`
-					FrontFemale      any    `json:"front_female"`
-					FrontShiny       string `json:"front_shiny"`
-					FrontShinyFemale any    `json:"front_shiny_female"`
-				} `json:"omegaruby-alphasapphire"`
-				XY struct {
-					FrontDefault     string `json:"front_default"`
-					FrontFemale      any    `json:"front_female"`
-					FrontShiny       string `json:"front_shiny"`
-					FrontShinyFemale any    `json:"front_shiny_female"`
-				} `json:"x-y"`
-			} `json:"generation-vi"`
-			GenerationVii struct {
-				Icons struct {
-					FrontDefault string `json:"front_default"`
-					FrontFemale  any    `json:"front_female"`
-				} `json:"icons"`
-				UltraSunUltraMoon struct {
-					FrontDefault     string `json:"front_default"`
-					FrontFemale      any    `json:"front_female"`
-					FrontShiny       string `json:"front_shiny"`
-					FrontShinyFemale any    `json:"front_shiny_female"`
-				} `json:"ultra-sun-ultra-moon"`
-			} `json:"generation-vii"`
-			GenerationViii struct {
-				Icons struct {
-					FrontDefault string `json:"front_default"`
-					FrontFemale  any    `json:"front_female"`
-				} `json:"icons"`
-			} `json:"generation-viii"`
-		} `json:"versions"`
-	} `json:"sprites"`
-	Stats []struct {
-		BaseStat int `json:"base_stat"`
-		Effort   int `json:"effort"`
-		Stat     struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"stat"`
-	} `json:"stats"`
-	Types []struct {
-		Slot int `json:"slot"`
-		Type struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"type"`
-	} `json:"types"`
-	Weight int `json:"weight"`
+// runOnce executes a single command from the command line and returns the
+// process exit status: 0 on success, 1 when the command fails and 2 when it
+// doesn't exist.
+func runOnce(session *cli.Session, args []string) int {
+	// Ctrl-C cancels the command, which lets serve shut down cleanly.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	err := session.Exec(ctx, os.Stdout, args)
+	// Only the command line itself gets the usage status, an unknown command
+	// inside a script is one more failing line.
+	if _, ok := err.(cli.UnknownCommandError); ok {
+		fmt.Fprintln(os.Stderr, fmt.Sprintf("%v, run `pokedex help` to list them.", err))
+		return 2
+	}
+	if err != nil && !errors.Is(err, cli.ErrExit) {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
 }
 
-type config struct {
-	Next     string
-	Previous string
+func stdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
 }
 
-func commandExit(_ ...string) error {
-	os.Exit(0)
-	return nil
+// options are the leading command line options: --config picks another
+// config file, --record dir and --replay dir capture the API responses as
+// fixture files or play them back offline, and a flag per setting such as
+// --api-url overrides both the config file and the environment.
+type options struct {
+	configPath string
+	record     string
+	replay     string
+	settings   [][2]string
 }
 
-func printLocations(response response) {
-	for _, result := range response.Results {
-		fmt.Println(result.Name)
+func parseOptions(args []string) (options, []string, error) {
+	opts := options{configPath: configPath()}
+	flags := map[string]string{}
+	for _, name := range config.Keys() {
+		flags[config.FlagName(name)] = name
 	}
-}
-
-func printPokemons(area area) {
-	fmt.Println("Exploring pastoria-city-area...")
-	fmt.Println("Found Pokemon:")
-	for _, data := range area.PokemonEncounters {
-		fmt.Println(" -", data.Pokemon.Name)
+	for len(args) > 0 {
+		name, isSetting := flags[args[0]]
+		if !isSetting && args[0] != "--config" && args[0] != "--record" && args[0] != "--replay" {
+			break
+		}
+		if len(args) < 2 {
+			return opts, args, fmt.Errorf("The %v option needs a value", args[0])
+		}
+		switch {
+		case isSetting:
+			opts.settings = append(opts.settings, [2]string{name, args[1]})
+		case args[0] == "--config":
+			opts.configPath = args[1]
+		case args[0] == "--record":
+			opts.record = args[1]
+		default:
+			opts.replay = args[1]
+		}
+		args = args[2:]
 	}
+	// Replaying answers every request, so nothing would reach the recorder.
+	if opts.record != "" && opts.replay != "" {
+		return opts, args, errors.New("The --record and --replay options can't be used together")
+	}
+	return opts, args, nil
 }
 
-func main() {
-	config := config{
-		Next:     "https://pokeapi.co/api/v2/location-area/?offset=0&limit=20",
-		Previous: "",
+// loadConfig layers the config file, the POKEDEX_* variables and the flags
+// over the defaults, each overriding the ones before.
+func loadConfig(opts options) (config.Config, error) {
+	cfg, err := config.Load(opts.configPath)
+	if err != nil {
+		return cfg, err
 	}
-
-	interval := time.Minute
-	catch := pokecache.NewCache(interval)
-	pokedex := map[string]pokemon{}
-
-	commandCatch := func(opts ...string) error {
-		if len(opts) < 1 {
-			return errors.New("The catch command need a pokemon name as argument")
-		}
-		_, ok := pokedex[opts[0]]
-		if ok {
-			fmt.Println("Pokemon already captured.")
-			return nil
-		}
-		target := fmt.Sprintf("https://pokeapi.co/api/v2/pokemon/%v", opts[0])
-		responseBytes, err := api.GetLocations(target)
-		if err != nil {
-			return err
-		}
-		byteBodyResponse := responseBytes
-		pokemonDetails := pokemon{}
-		err1 := json.Unmarshal(byteBodyResponse, &pokemonDetails)
-		if err1 != nil {
-			return err1
-		}
-
-		fmt.Println(fmt.Sprintf("Throwing a Pokeball at %v...", opts[0]))
-		if float64(pokemonDetails.BaseExperience)*rand.NormFloat64() < 10 {
-			pokedex[opts[0]] = pokemonDetails
-			fmt.Println(fmt.Sprintf("%v was caught!", opts[0]))
-		} else {
-			fmt.Println(fmt.Sprintf("%v escaped!", opts[0]))
-		}
-		return nil
+	if err := cfg.ApplyEnv(os.LookupEnv); err != nil {
+		return cfg, err
 	}
-
-	commandInspect := func(opts ...string) error {
-		if len(opts) < 1 {
-			return errors.New("The catch command need a pokemon name as argument")
-		}
-		pokemonDetails, ok := pokedex[opts[0]]
-		if !ok {
-			fmt.Println("you have not caught that pokemon")
-			return nil
-		}
-		detailsString := fmt.Sprintf("Name: %v\nHeight: %v\nWeight: %v\nStats:", pokemonDetails.Forms[0].Name, pokemonDetails.Height, pokemonDetails.Weight)
-
-		fmt.Println(detailsString)
-		for _, stats := range pokemonDetails.Stats {
-			line := fmt.Sprintf(" - %v: %v", stats.Stat.Name, stats.BaseStat)
-			fmt.Println(line)
-		}
-		fmt.Println("Types:")
-		for _, types := range pokemonDetails.Types {
-			line := fmt.Sprintf(" - %v", types.Type.Name)
-			fmt.Println(line)
+	for _, setting := range opts.settings {
+		if err := cfg.Set(setting[0], setting[1], config.SourceFlag); err != nil {
+			return cfg, fmt.Errorf("%v: %w", config.FlagName(setting[0]), err)
 		}
-		return nil
 	}
+	return cfg, nil
+}
 
-	commandPokedex := func(opts ...string) error {
-		if len(opts) < 0 {
-			return errors.New("The pokedex command dont expect arguments.")
-		}
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
 
-		fmt.Println("Your Pokedex:")
-		for _, pokemon := range pokedex {
-			fmt.Println(" -", pokemon.Forms[0].Name)
-		}
-		return nil
+// run is main without os.Exit, so the deferred cleanups run before the
+// process exits with the returned status.
+func run(args []string) int {
+	opts, args, err := parseOptions(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
 	}
-
-	commandExplore := func(opts ...string) error {
-		var byteBodyResponse []byte
-		if len(opts) < 1 {
-			return errors.New("The explore command needs one area name")
-		}
-		target := fmt.Sprintf("https://pokeapi.co/api/v2/location-area/%v/", opts[0])
-		cacheBytes, ok := catch.Get(target)
-		if ok {
-			byteBodyResponse = cacheBytes
-		} else {
-			responseBytes, err := api.GetLocations(target)
-			if err != nil {
-				return err
-			}
-			byteBodyResponse = responseBytes
-			catch.Add(target, byteBodyResponse)
-		}
-		areaDetails := area{}
-		err1 := json.Unmarshal(byteBodyResponse, &areaDetails)
-		if err1 != nil {
-			return err1
-		}
-
-		printPokemons(areaDetails)
-		return nil
+	cfg, err := loadConfig(opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
 	}
-
-	commandMap := func(_ ...string) error {
-		var byteBodyResponse []byte
-		cacheBytes, ok := catch.Get(config.Next)
-		if ok {
-			byteBodyResponse = cacheBytes
-		} else {
-			responseBytes, err := api.GetLocations(config.Next)
-			if err != nil {
-				return err
-			}
-			byteBodyResponse = responseBytes
-		}
-		catch.Add(config.Next, byteBodyResponse)
-		response := response{}
-		err1 := json.Unmarshal(byteBodyResponse, &response)
-		if err1 != nil {
-			return err1
-		}
-		config.Next = response.Next
-		config.Previous = response.Previous
-
-		printLocations(response)
-		return nil
+	client := api.NewClient(cfg.APIURL, cfg.Timeout)
+	if opts.record != "" {
+		client = client.WithTransport(api.Recorder{Dir: opts.record})
+	}
+	if opts.replay != "" {
+		client = client.WithTransport(api.Replayer{Dir: opts.replay})
+	}
+	catch := pokecache.NewBoundedCache(cfg.CacheTTL, pokecache.Limits{MaxEntries: cfg.CacheMaxEntries, MaxBytes: cfg.CacheMaxBytes})
+	defer catch.Close()
+	format, style := outputStyle()
+	session, err := cli.NewSession(cli.Options{
+		Client:     client,
+		Cache:      catch,
+		Config:     cfg,
+		ConfigPath: opts.configPath,
+		DataDir:    dataPath(""),
+		IndexPath:  indexPath(),
+		Format:     format,
+		Style:      style,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
 	}
+	ctx := context.Background()
 
-	commandMapB := func(_ ...string) error {
-		if config.Previous == "" {
-			config.Next = "https://pokeapi.co/api/v2/location-area/?offset=0&limit=20"
-			return nil
+	// A lone --output option sets the format of the REPL session.
+	if len(args) == 2 && (args[0] == "--output" || args[0] == "-o") || len(args) == 1 && strings.HasPrefix(args[0], "--output=") {
+		if err := session.Exec(ctx, os.Stdout, args); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
 		}
-		var byteBodyResponse []byte
-		cacheBytes, ok := catch.Get(config.Previous)
-		if ok {
-			byteBodyResponse = cacheBytes
-		} else {
-			responseBytes, err := api.GetLocations(config.Previous)
-			if err != nil {
-				return err
-			}
-			byteBodyResponse = responseBytes
-		}
-		catch.Add(config.Previous, byteBodyResponse)
-		response := response{}
-		err1 := json.Unmarshal(byteBodyResponse, &response)
-		if err1 != nil {
-			return err1
-		}
-		config.Next = response.Next
-		config.Previous = response.Previous
-
-		printLocations(response)
-		return nil
+		args = nil
 	}
-
-	commands := make(map[string]cliCommand)
-
-	commandHelp := func(opts ...string) error {
-		fmt.Println("Welcome to the Pokedex!\nUsage:")
-		for _, command := range commands {
-			fmt.Println(fmt.Sprintf("%v: %v", command.name, command.description))
+	if len(args) > 0 {
+		// `pokedex run script.pdx` reads better from a shell than `source`.
+		if args[0] == "run" {
+			args[0] = "source"
 		}
-		return nil
+		return runOnce(session, args)
 	}
 
 	scanner := bufio.NewScanner(os.Stdin)
-
-	commands = map[string]cliCommand{
-		"help": {
-			name:        "help",
-			description: "Displays a help message",
-			callback:    commandHelp,
-		},
-		"exit": {
-			name:        "exit",
-			description: "Exit the Pokedex",
-			callback:    commandExit,
-		},
-		"map": {
-			name:        "map",
-			description: "Discover new areas",
-			callback:    commandMap,
-		},
-		"mapb": {
-			name:        "mapb",
-			description: "Diplay previous areas",
-			callback:    commandMapB,
-		},
-		"explore": {
-			name:        "explore",
-			description: "List pokemons in an area",
-			callback:    commandExplore,
-		},
-		"catch": {
-			name:        "catch",
-			description: "Attempt to capture a pokemon",
-			callback:    commandCatch,
-		},
-		"inspect": {
-			name:        "inspect",
-			description: "Print stats about a pokemon",
-			callback:    commandInspect,
-		},
-		"pokedex": {
-			name:        "pokedex",
-			description: "Print all the captured pokemon names",
-			callback:    commandPokedex,
-		},
+	interactive := stdinIsTerminal()
+	prompt := func() {
+		if interactive {
+			fmt.Printf("pokedex (%v) > ", session.Trainer())
+		}
 	}
-
-	fmt.Print("pokedex > ")
+	prompt()
 	for scanner.Scan() {
-		inputs := strings.Split(scanner.Text(), " ")
-		if cmd, ok := commands[inputs[0]]; ok {
-			cmd.callback(inputs[1:]...)
+		// Ctrl-C cancels the running command, which stops serve and returns
+		// to the prompt. At the prompt itself it still quits.
+		lineCtx, stop := signal.NotifyContext(ctx, os.Interrupt)
+		err := session.ExecLine(lineCtx, os.Stdout, scanner.Text())
+		stop()
+		if errors.Is(err, cli.ErrExit) {
+			return 0
+		}
+		if err != nil {
+			fmt.Println(err)
 		}
-		fmt.Print("pokedex > ")
+		prompt()
 	}
+	return 0
 }
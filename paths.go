@@ -0,0 +1,30 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// dataPath places persistent files under $XDG_DATA_HOME/pokedex, falling back
+// to ~/.local/share/pokedex as the XDG spec suggests.
+func dataPath(name string) string {
+	dir := os.Getenv("XDG_DATA_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return name
+		}
+		dir = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(dir, "pokedex", name)
+}
+
+// configPath is $XDG_CONFIG_HOME/pokedex/config.toml, or
+// ~/.config/pokedex/config.toml when the variable isn't set.
+func configPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "pokedex", "config.toml")
+}